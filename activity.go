@@ -0,0 +1,86 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrActivityNotInstalled is returned by Activities when the
+// Activity app is not enabled on the server.
+var ErrActivityNotInstalled = errors.New("the Activity app is not installed")
+
+// Activity is an entry of the activity feed of the user, reporting
+// who did what.
+type Activity struct {
+	Id int `xml:"activity_id" json:"activity_id"`
+
+	// App is the app which published the activity, e.g. "files",
+	// and Type its type, e.g. "file_created".
+	App  string `xml:"app" json:"app"`
+	Type string `xml:"type" json:"type"`
+
+	// User is the user who caused the activity, empty for
+	// activities caused by the system.
+	User    string `xml:"user" json:"user"`
+	Subject string `xml:"subject" json:"subject"`
+
+	// ObjectType is the type of the object of the activity, e.g.
+	// "files", and ObjectName its name, which is the path of the
+	// file relative to the user's root for files.
+	ObjectType string `xml:"object_type" json:"object_type"`
+	ObjectId   int    `xml:"object_id" json:"object_id"`
+	ObjectName string `xml:"object_name" json:"object_name"`
+
+	Time time.Time `xml:"datetime" json:"datetime"`
+}
+
+// Activities returns at most limit activities of the feed of the
+// user, newest first, starting after the activity whose id is since.
+// A zero since starts from the newest activity and a zero limit uses
+// the default of the server, 50. The next page is the one following
+// the id of the last activity returned; an empty result means there
+// are no more activities.
+func (c *Client) Activities(since int, limit int) ([]Activity, error) {
+	ok, err := c.hasCapability("activity")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrActivityNotInstalled
+	}
+
+	query := url.Values{}
+	if since > 0 {
+		query.Set("since", strconv.Itoa(since))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	endpoint := "ocs/v2.php/apps/activity/api/v2/activity?" + query.Encode()
+
+	body, meta, err := c.sendOCSRequest(context.Background(), "GET", endpoint, "")
+	if err != nil {
+		return nil, err
+	}
+	switch meta.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		// The server replies so when there are no more
+		// activities.
+		return nil, nil
+	default:
+		return nil, meta.err()
+	}
+
+	result := struct {
+		Activities []Activity `xml:"element" json:"element"`
+	}{}
+	if err := c.unmarshalOCS(body, &result); err != nil {
+		return nil, err
+	}
+	return result.Activities, nil
+}
@@ -0,0 +1,79 @@
+package cloud
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func (t *testSuite) TestActivities() {
+	var installed bool
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ocs/v2.php/cloud/capabilities":
+			capabilities := "<core><pollinterval>60</pollinterval></core>"
+			if installed {
+				capabilities += "<activity><apiv2><element>filters</element></apiv2></activity>"
+			}
+			fmt.Fprintf(w, `<?xml version="1.0"?><ocs><meta><statuscode>200</statuscode></meta><data><capabilities>%s</capabilities></data></ocs>`, capabilities)
+		case "/ocs/v2.php/apps/activity/api/v2/activity":
+			query = r.URL.RawQuery
+			if r.URL.Query().Get("since") == "12" {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode><message>OK</message></meta><data>
+<element><activity_id>13</activity_id><app>files</app><type>file_changed</type><affecteduser>admin</affecteduser><user>bob</user><timestamp>1700000000</timestamp><subject>bob changed a.txt</subject><message></message><object_type>files</object_type><object_id>42</object_id><object_name>/Test/a.txt</object_name><link>https://cloud.example.com/f/42</link><datetime>2023-11-14T22:13:20+00:00</datetime></element>
+<element><activity_id>12</activity_id><app>files_sharing</app><type>shared</type><affecteduser>admin</affecteduser><user></user><subject>Shared with you</subject><object_type>files</object_type><object_id>43</object_id><object_name>/Shared</object_name><datetime>2023-11-14T21:00:00+00:00</datetime></element>
+</data></ocs>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	_, err = c.Activities(0, 0)
+	t.Equal(ErrActivityNotInstalled, err)
+
+	installed = true
+	activities, err := c.Activities(0, 2)
+	t.Nil(err)
+	t.Equal("limit=2", query)
+	times := []int64{1700000000, 1699995600}
+	t.Equal(len(times), len(activities))
+	for i := 0; i < len(times) && i < len(activities); i++ {
+		t.Equal(times[i], activities[i].Time.Unix())
+		activities[i].Time = time.Time{}
+	}
+	t.Equal([]Activity{
+		{
+			Id:         13,
+			App:        "files",
+			Type:       "file_changed",
+			User:       "bob",
+			Subject:    "bob changed a.txt",
+			ObjectType: "files",
+			ObjectId:   42,
+			ObjectName: "/Test/a.txt",
+		},
+		{
+			Id:         12,
+			App:        "files_sharing",
+			Type:       "shared",
+			Subject:    "Shared with you",
+			ObjectType: "files",
+			ObjectId:   43,
+			ObjectName: "/Shared",
+		},
+	}, activities)
+
+	activities, err = c.Activities(12, 2)
+	t.Nil(err)
+	t.Equal("limit=2&since=12", query)
+	t.Equal(0, len(activities))
+}
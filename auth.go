@@ -0,0 +1,47 @@
+package cloud
+
+import "net/http"
+
+// Authenticator adds credentials to the requests sent to the server.
+type Authenticator interface {
+	Apply(req *http.Request)
+}
+
+// BasicAuth authenticates with HTTP Basic authentication. The
+// password can be the main password of the user or an app password.
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+func (a BasicAuth) Apply(req *http.Request) {
+	req.SetBasicAuth(a.Username, a.Password)
+}
+
+// BearerToken authenticates with an OAuth2 access token.
+type BearerToken string
+
+func (t BearerToken) Apply(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+string(t))
+}
+
+// DialWithAuth is like Dial, authenticating the requests with auth.
+// The username is still required, as it is part of the paths of some
+// endpoints.
+func DialWithAuth(host, username string, auth Authenticator) (*Client, error) {
+	c, err := Dial(host, username, "")
+	if err != nil {
+		return nil, err
+	}
+	c.Auth = auth
+	return c, nil
+}
+
+// authenticate adds the credentials of the client to req.
+func (c *Client) authenticate(req *http.Request) {
+	if c.Auth != nil {
+		c.Auth.Apply(req)
+		return
+	}
+	req.SetBasicAuth(c.Username, c.Password)
+}
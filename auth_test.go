@@ -0,0 +1,33 @@
+package cloud
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+func (t *testSuite) TestAuthenticator() {
+	var authorization []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = append(authorization, r.Header.Get("Authorization"))
+		if r.URL.Path == "/ocs/v2.php/apps/files_sharing/api/v1/shares" {
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data/></ocs>`)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := DialWithAuth(ts.URL+"/", "admin", BearerToken("secret"))
+	t.Nil(err)
+	_, err = c.Download("test.txt")
+	t.Nil(err)
+	_, err = c.ListShares()
+	t.Nil(err)
+	t.Equal([]string{"Bearer secret", "Bearer secret"}, authorization)
+
+	authorization = nil
+	c, err = DialWithAuth(ts.URL+"/", "admin", BasicAuth{Username: "admin", Password: "app-password"})
+	t.Nil(err)
+	_, err = c.Download("test.txt")
+	t.Nil(err)
+	t.Equal([]string{"Basic YWRtaW46YXBwLXBhc3N3b3Jk"}, authorization)
+}
@@ -0,0 +1,126 @@
+package cloud
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchSummary reports the outcome of the operations of a Batch,
+// identified by their names.
+type BatchSummary struct {
+	// Completed are the operations which succeeded.
+	Completed []string
+
+	// Failed are the operations which returned an error, and
+	// Errors the respective errors.
+	Failed []string
+	Errors []error
+
+	// Cancelled are the operations which were never started
+	// because the batch was cancelled.
+	Cancelled []string
+}
+
+// Err returns the error of the first failed operation, or nil.
+func (s *BatchSummary) Err() error {
+	if len(s.Errors) == 0 {
+		return nil
+	}
+	return s.Errors[0]
+}
+
+// Batch runs operations concurrently with a bounded number of
+// workers and supports a graceful shutdown: after Cancel, or when
+// the parent context is done, no further operation is started, the
+// running ones see their context cancelled and Wait returns once all
+// the goroutines are gone.
+type Batch struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers chan struct{}
+	wg      sync.WaitGroup
+
+	mu      sync.Mutex
+	summary BatchSummary
+}
+
+// NewBatch returns a batch running at most workers operations at a
+// time, cancelled when ctx is done.
+func NewBatch(ctx context.Context, workers int) *Batch {
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &Batch{
+		ctx:     ctx,
+		cancel:  cancel,
+		workers: make(chan struct{}, workers),
+	}
+}
+
+// Go starts the operation f, identified by name, as soon as a worker
+// is available. It reports whether f was started: if the batch is
+// cancelled first, f is recorded as cancelled instead.
+func (b *Batch) Go(name string, f func(ctx context.Context) error) bool {
+	select {
+	case b.workers <- struct{}{}:
+	case <-b.ctx.Done():
+		b.record(name, b.ctx.Err())
+		return false
+	}
+	if b.ctx.Err() != nil {
+		<-b.workers
+		b.record(name, b.ctx.Err())
+		return false
+	}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer func() { <-b.workers }()
+		b.record(name, f(b.ctx))
+	}()
+	return true
+}
+
+// run runs f, identified by name, in the calling goroutine unless the
+// batch is cancelled. It returns the error of f or of the context.
+func (b *Batch) run(name string, f func(ctx context.Context) error) error {
+	err := b.ctx.Err()
+	if err == nil {
+		err = f(b.ctx)
+	}
+	b.record(name, err)
+	return err
+}
+
+// record adds the outcome of an operation to the summary.
+func (b *Batch) record(name string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case err == nil:
+		b.summary.Completed = append(b.summary.Completed, name)
+	case err == context.Canceled || err == context.DeadlineExceeded:
+		b.summary.Cancelled = append(b.summary.Cancelled, name)
+	default:
+		b.summary.Failed = append(b.summary.Failed, name)
+		b.summary.Errors = append(b.summary.Errors, err)
+	}
+}
+
+// Cancel stops the batch: pending operations are not started and
+// the context of the running ones is cancelled.
+func (b *Batch) Cancel() {
+	b.cancel()
+}
+
+// Wait waits for all the operations to return and reports their
+// outcome.
+func (b *Batch) Wait() BatchSummary {
+	b.wg.Wait()
+	b.cancel()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.summary
+}
@@ -0,0 +1,167 @@
+package cloud
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// bulkResult is the outcome of the upload of a single file, as
+// returned by the bulk upload endpoint.
+type bulkResult struct {
+	Error   bool   `json:"error"`
+	Message string `json:"message"`
+	Etag    string `json:"etag"`
+}
+
+// BulkUpload uploads many files with a single request to the bulk
+// upload endpoint of Nextcloud 22 and later, which is much faster
+// than one PUT per file when files are small. Keys of files are the
+// destination paths, relative to the user's root. The destination
+// folders must exist. The returned paths are sorted and errs is
+// aligned with them: the i-th error is the outcome of the upload of
+// the i-th path and is nil on success.
+func (c *Client) BulkUpload(files map[string][]byte) ([]string, []error) {
+	start := time.Now()
+	paths, errs := c.bulkUpload(files)
+	for i, p := range paths {
+		c.emit(OpUpload, p, int64(len(files[p])), start, errs[i])
+	}
+	return paths, errs
+}
+
+// bulkUpload uploads files with a single request, see BulkUpload.
+func (c *Client) bulkUpload(files map[string][]byte) ([]string, []error) {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	errs := make([]error, len(paths))
+
+	fail := func(err error) ([]string, []error) {
+		for i := range errs {
+			errs[i] = err
+		}
+		return paths, errs
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	mtime := strconv.FormatInt(time.Now().Unix(), 10)
+	for _, p := range paths {
+		data := files[p]
+		sum := md5.Sum(data)
+		part, err := w.CreatePart(textproto.MIMEHeader{
+			"X-File-Path":    {path.Clean("/" + p)},
+			"X-File-Md5":     {hex.EncodeToString(sum[:])},
+			"X-File-Mtime":   {mtime},
+			"Content-Length": {strconv.Itoa(len(data))},
+		})
+		if err != nil {
+			return fail(err)
+		}
+		if _, err := part.Write(data); err != nil {
+			return fail(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return fail(err)
+	}
+
+	req, err := c.newRequest("POST", "remote.php/dav/bulk", &body)
+	if err != nil {
+		return fail(err)
+	}
+	req.Header.Set("Content-Type", "multipart/related; boundary="+w.Boundary())
+
+	resp, err := c.do(req)
+	if err != nil {
+		return fail(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fail(newStatusError(resp))
+	}
+
+	results := make(map[string]bulkResult)
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return fail(err)
+	}
+
+	for i, p := range paths {
+		result, ok := results[path.Clean("/"+p)]
+		switch {
+		case !ok:
+			errs[i] = errors.New("missing from the bulk upload response")
+		case result.Error:
+			errs[i] = errors.New(result.Message)
+		}
+	}
+
+	return paths, errs
+}
+
+// BatchUploadError is returned by UploadBatch when some of the files
+// couldn't be uploaded.
+type BatchUploadError struct {
+	// Failed are the sorted destination paths of the files which
+	// couldn't be uploaded, and Errors the respective errors.
+	Failed []string
+	Errors []error
+}
+
+func (e *BatchUploadError) Error() string {
+	return fmt.Sprintf("%d files of the batch failed to upload, first %s: %v", len(e.Failed), e.Failed[0], e.Errors[0])
+}
+
+// UploadBatch uploads many files, whose keys are the destination
+// paths relative to the user's root, with a single request to the
+// bulk upload endpoint when the server advertises it, see
+// BulkUpload, and with one PUT per file otherwise. The destination
+// folders must exist. If some of the files can't be uploaded, the
+// returned error is a *BatchUploadError listing them.
+func (c *Client) UploadBatch(files map[string][]byte) error {
+	capabilities, err := c.Capabilities()
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	var errs []error
+	if _, ok := capabilities.Value("dav", "bulkupload"); ok {
+		paths, errs = c.BulkUpload(files)
+	} else {
+		for p := range files {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		errs = make([]error, len(paths))
+		for i, p := range paths {
+			errs[i] = c.Upload(files[p], p)
+		}
+	}
+
+	batchErr := new(BatchUploadError)
+	for i, err := range errs {
+		if err != nil {
+			batchErr.Failed = append(batchErr.Failed, paths[i])
+			batchErr.Errors = append(batchErr.Errors, err)
+		}
+	}
+	if len(batchErr.Failed) > 0 {
+		return batchErr
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package cloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+)
+
+func (t *testSuite) TestBulkUpload() {
+	received := map[string]string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Equal("/remote.php/dav/bulk", r.URL.Path)
+		mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		t.Nil(err)
+		t.Equal("multipart/related", mediaType)
+
+		results := map[string]interface{}{}
+		reader := multipart.NewReader(r.Body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			data, _ := ioutil.ReadAll(part)
+			p := part.Header.Get("X-File-Path")
+			received[p] = string(data)
+			if p == "/Missing/c.txt" {
+				results[p] = map[string]interface{}{"error": true, "message": "Parent folder not found"}
+			} else {
+				results[p] = map[string]interface{}{"error": false, "etag": "abc"}
+			}
+		}
+		json.NewEncoder(w).Encode(results)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	paths, errs := c.BulkUpload(map[string][]byte{
+		"Test/b.txt":    []byte("b"),
+		"Test/a.txt":    []byte("a"),
+		"Missing/c.txt": []byte("c"),
+	})
+	t.Equal([]string{"Missing/c.txt", "Test/a.txt", "Test/b.txt"}, paths)
+	t.Equal(3, len(errs))
+	t.NotNil(errs[0])
+	t.Nil(errs[1])
+	t.Nil(errs[2])
+	t.Equal(map[string]string{"/Test/a.txt": "a", "/Test/b.txt": "b", "/Missing/c.txt": "c"}, received)
+}
+
+func (t *testSuite) TestUploadBatch() {
+	dav := newDavServer()
+	defer dav.Close()
+	dav.dirs["/Test"] = true
+
+	bulk := false
+	var bulkRequests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ocs/v2.php/cloud/capabilities":
+			capabilities := `<core><pollinterval>60</pollinterval></core>`
+			if bulk {
+				capabilities += `<dav><bulkupload>1.0</bulkupload></dav>`
+			}
+			fmt.Fprintf(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode><message>OK</message></meta><data><capabilities>%s</capabilities></data></ocs>`, capabilities)
+		case "/remote.php/dav/bulk":
+			bulkRequests++
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"/Test/a.txt":    map[string]interface{}{"error": false, "etag": "abc"},
+				"/Missing/b.txt": map[string]interface{}{"error": true, "message": "Parent folder not found"},
+			})
+		default:
+			dav.ServeHTTP(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+	files := map[string][]byte{
+		"Test/a.txt":    []byte("a"),
+		"Missing/b.txt": []byte("b"),
+	}
+
+	err = c.UploadBatch(files)
+	batchErr, ok := err.(*BatchUploadError)
+	t.True(ok)
+	if ok {
+		t.Equal([]string{"Missing/b.txt"}, batchErr.Failed)
+		t.Equal(1, len(batchErr.Errors))
+	}
+	t.Equal("a", string(dav.files["/Test/a.txt"]))
+	t.Equal([]string{"PUT /Missing/b.txt", "PUT /Test/a.txt"}, dav.requests)
+	t.Equal(0, bulkRequests)
+
+	bulk = true
+	dav.requests = nil
+	err = c.UploadBatch(files)
+	batchErr, ok = err.(*BatchUploadError)
+	t.True(ok)
+	if ok {
+		t.Equal([]string{"Missing/b.txt"}, batchErr.Failed)
+	}
+	t.Nil(dav.requests)
+	t.Equal(1, bulkRequests)
+
+	bulk = false
+	t.Nil(c.UploadBatch(map[string][]byte{"Test/c.txt": []byte("c")}))
+}
@@ -0,0 +1,82 @@
+package cloud
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Calendar is a calendar of the user, served by the CalDAV endpoint.
+type Calendar struct {
+	// Name is the last element of the calendar URL, e.g.
+	// "personal".
+	Name string
+
+	// Href is the path of the calendar on the server.
+	Href string
+
+	DisplayName string
+
+	// Color is the color of the calendar, e.g. "#0082c9", if set.
+	Color string
+}
+
+// calendarsRoot returns the path of the user's calendars on the DAV
+// endpoint.
+func (c *Client) calendarsRoot() string {
+	return path.Join("remote.php/dav/calendars", c.Username)
+}
+
+// ListCalendars returns the calendars of the user.
+func (c *Client) ListCalendars() ([]Calendar, error) {
+	result, err := c.davPropfind(c.calendarsRoot()+"/", "1", "<d:resourcetype/><d:displayname/><ical:calendar-color/>")
+	if err != nil {
+		return nil, err
+	}
+
+	var calendars []Calendar
+	for _, response := range result.Responses {
+		prop := response.prop()
+		if prop.ResourceType.Calendar == nil {
+			continue
+		}
+		calendars = append(calendars, Calendar{
+			Name:        path.Base(strings.TrimSuffix(response.Href, "/")),
+			Href:        response.Href,
+			DisplayName: prop.DisplayName,
+			Color:       prop.CalendarColor,
+		})
+	}
+
+	return calendars, nil
+}
+
+// CreateCalendar creates a calendar with the given name, used in its
+// URL, and display name.
+func (c *Client) CreateCalendar(name, displayName string) error {
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(displayName))
+	body := `<?xml version="1.0" encoding="UTF-8"?>
+<cal:mkcalendar xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav"><d:set><d:prop><d:displayname>` +
+		escaped.String() + `</d:displayname></d:prop></d:set></cal:mkcalendar>`
+
+	req, err := c.newRequest("MKCALENDAR", path.Join(c.calendarsRoot(), name), strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return newStatusError(resp)
+	}
+
+	return nil
+}
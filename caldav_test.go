@@ -0,0 +1,57 @@
+package cloud
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+func (t *testSuite) TestCalendars() {
+	var created string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "MKCALENDAR":
+			body, _ := ioutil.ReadAll(r.Body)
+			t.True(strings.Contains(string(body), "<d:displayname>Work &amp; Co</d:displayname>"))
+			created = r.URL.Path
+			w.WriteHeader(http.StatusCreated)
+		case "PROPFIND":
+			t.Equal("/remote.php/dav/calendars/admin/", r.URL.Path)
+			w.WriteHeader(http.StatusMultiStatus)
+			fmt.Fprint(w, `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:cal="urn:ietf:params:xml:ns:caldav" xmlns:x1="http://apple.com/ns/ical/">
+ <d:response>
+  <d:href>/remote.php/dav/calendars/admin/</d:href>
+  <d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+ <d:response>
+  <d:href>/remote.php/dav/calendars/admin/personal/</d:href>
+  <d:propstat><d:prop><d:resourcetype><d:collection/><cal:calendar/></d:resourcetype><d:displayname>Personal</d:displayname><x1:calendar-color>#0082c9</x1:calendar-color></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+ <d:response>
+  <d:href>/remote.php/dav/calendars/admin/inbox/</d:href>
+  <d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+</d:multistatus>`)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	calendars, err := c.ListCalendars()
+	t.Nil(err)
+	t.Equal([]Calendar{{
+		Name:        "personal",
+		Href:        "/remote.php/dav/calendars/admin/personal/",
+		DisplayName: "Personal",
+		Color:       "#0082c9",
+	}}, calendars)
+
+	err = c.CreateCalendar("work", "Work & Co")
+	t.Nil(err)
+	t.Equal("/remote.php/dav/calendars/admin/work", created)
+}
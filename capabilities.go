@@ -0,0 +1,243 @@
+package cloud
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// xmlNode is a generic XML element, used to walk documents whose
+// shape depends on the server configuration.
+type xmlNode struct {
+	XMLName xml.Name
+	Content string    `xml:",chardata"`
+	Nodes   []xmlNode `xml:",any"`
+}
+
+// UnmarshalJSON decodes a JSON document as the equivalent XML one of
+// the OCS API: the items of a list are element tags and booleans are
+// "1" or "".
+func (n *xmlNode) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return err
+	}
+	*n = jsonNode(n.XMLName, v)
+	return nil
+}
+
+// jsonNode returns the node named name equivalent to the decoded
+// JSON value v.
+func jsonNode(name xml.Name, v interface{}) xmlNode {
+	n := xmlNode{XMLName: name}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			n.Nodes = append(n.Nodes, jsonNode(xml.Name{Local: key}, v[key]))
+		}
+	case []interface{}:
+		for _, item := range v {
+			n.Nodes = append(n.Nodes, jsonNode(xml.Name{Local: "element"}, item))
+		}
+	case bool:
+		if v {
+			n.Content = "1"
+		}
+	case string:
+		n.Content = v
+	case json.Number:
+		n.Content = v.String()
+	}
+	return n
+}
+
+// child returns the descendant of n found following the given
+// element names, or nil.
+func (n *xmlNode) child(names ...string) *xmlNode {
+	if len(names) == 0 {
+		return n
+	}
+	for i := range n.Nodes {
+		if n.Nodes[i].XMLName.Local == names[0] {
+			return n.Nodes[i].child(names[1:]...)
+		}
+	}
+	return nil
+}
+
+// Capabilities are the features advertised by the server, grouped by
+// app. Their names and values are documented by each app.
+type Capabilities struct {
+	root xmlNode
+}
+
+// Has reports whether the given app, e.g. "files_sharing",
+// advertises its capabilities, which means that it is installed and
+// enabled.
+func (c *Capabilities) Has(app string) bool {
+	return c.root.child(app) != nil
+}
+
+// Value returns the value of the capability found following the
+// given names, e.g. Value("files_sharing", "public", "password",
+// "enforced"), and whether it exists.
+func (c *Capabilities) Value(names ...string) (string, bool) {
+	n := c.root.child(names...)
+	if n == nil {
+		return "", false
+	}
+	return n.Content, true
+}
+
+// Values returns the items of the list capability found following
+// the given names, e.g. Values("checksums", "supportedTypes"), or nil
+// if it doesn't exist.
+func (c *Capabilities) Values(names ...string) []string {
+	n := c.root.child(names...)
+	if n == nil {
+		return nil
+	}
+	var values []string
+	for _, item := range n.Nodes {
+		values = append(values, item.Content)
+	}
+	return values
+}
+
+// Enabled reports whether the capability found following the given
+// names is set to a true value.
+func (c *Capabilities) Enabled(names ...string) bool {
+	value, _ := c.Value(names...)
+	return value == "1" || value == "true"
+}
+
+// Capabilities returns the capabilities advertised by the server.
+func (c *Client) Capabilities() (*Capabilities, error) {
+	result := struct {
+		Capabilities xmlNode `xml:"capabilities" json:"capabilities"`
+	}{}
+	err := c.sendOCS("GET", "cloud/capabilities", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &Capabilities{root: result.Capabilities}, nil
+}
+
+// hasCapability reports whether the given app advertises its
+// capabilities, which means that it is installed and enabled.
+func (c *Client) hasCapability(app string) (bool, error) {
+	capabilities, err := c.Capabilities()
+	if err != nil {
+		return false, err
+	}
+	return capabilities.Has(app), nil
+}
+
+// UploadLimits are the upload settings advertised by the server.
+type UploadLimits struct {
+	// BigFileChunking tells whether chunked uploads are supported.
+	BigFileChunking bool
+
+	// MaxChunkSize is the recommended size in bytes of the chunks
+	// of chunked uploads, zero if the server doesn't advertise it.
+	MaxChunkSize int64
+
+	// MaxParallelCount is the maximum number of chunks to upload
+	// concurrently, zero if the server doesn't advertise it.
+	MaxParallelCount int
+
+	// MaxFileSize is the largest content accepted by a single
+	// request, the upload_max_filesize setting of the server, zero
+	// if the server doesn't advertise it.
+	MaxFileSize int64
+}
+
+// defaultChunkSize is the size of the chunks of chunked uploads when
+// the server advertises no limit.
+const defaultChunkSize = 10 << 20
+
+// ChunkSize returns the size of the chunks suited to the server:
+// MaxChunkSize when advertised, otherwise a default size of 10 MiB
+// reduced to MaxFileSize if needed.
+func (l *UploadLimits) ChunkSize() int64 {
+	if l.MaxChunkSize > 0 {
+		return l.MaxChunkSize
+	}
+	if l.MaxFileSize > 0 && l.MaxFileSize < defaultChunkSize {
+		return l.MaxFileSize
+	}
+	return defaultChunkSize
+}
+
+// UploadLimits returns the upload settings advertised by the server
+// capabilities, which should be used to size the chunks of large
+// uploads so that they don't exceed the limits of proxies.
+func (c *Client) UploadLimits() (*UploadLimits, error) {
+	capabilities, err := c.Capabilities()
+	if err != nil {
+		return nil, err
+	}
+
+	limits := UploadLimits{
+		BigFileChunking: capabilities.Enabled("files", "bigfilechunking"),
+	}
+	if value, ok := capabilities.Value("files", "chunked_upload", "max_size"); ok {
+		limits.MaxChunkSize, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if value, ok := capabilities.Value("files", "chunked_upload", "max_parallel_count"); ok {
+		limits.MaxParallelCount, err = strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if value, ok := capabilities.Value("files", "upload_max_filesize"); ok {
+		limits.MaxFileSize, err = parseFileSize(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &limits, nil
+}
+
+// parseFileSize parses a size in bytes, possibly in the shorthand
+// notation of PHP settings, e.g. "512M". No limit, zero or negative,
+// is returned as zero.
+func parseFileSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	multiplier := int64(1)
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'k', 'K':
+			multiplier = 1 << 10
+		case 'm', 'M':
+			multiplier = 1 << 20
+		case 'g', 'G':
+			multiplier = 1 << 30
+		}
+		if multiplier > 1 {
+			s = s[:len(s)-1]
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, nil
+	}
+	return n * multiplier, nil
+}
@@ -0,0 +1,49 @@
+package cloud
+
+func (t *testSuite) TestUploadLimits() {
+	ts := newOCSServer(map[string]string{
+		"/ocs/v2.php/cloud/capabilities": `<version><major>28</major></version><capabilities>
+<core><pollinterval>60</pollinterval></core>
+<files><bigfilechunking>1</bigfilechunking><chunked_upload><max_size>104857600</max_size><max_parallel_count>5</max_parallel_count></chunked_upload><upload_max_filesize>512M</upload_max_filesize></files>
+</capabilities>`,
+	})
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	limits, err := c.UploadLimits()
+	t.Nil(err)
+	t.Equal(&UploadLimits{BigFileChunking: true, MaxChunkSize: 104857600, MaxParallelCount: 5, MaxFileSize: 512 << 20}, limits)
+	t.Equal(int64(104857600), limits.ChunkSize())
+
+	t.Equal(int64(defaultChunkSize), (&UploadLimits{}).ChunkSize())
+	t.Equal(int64(2<<20), (&UploadLimits{MaxFileSize: 2 << 20}).ChunkSize())
+}
+
+func (t *testSuite) TestCapabilities() {
+	ts := newOCSServer(map[string]string{
+		"/ocs/v2.php/cloud/capabilities": `<version><major>28</major></version><capabilities>
+<files><bigfilechunking>1</bigfilechunking></files>
+<files_sharing><api_enabled>1</api_enabled><public><enabled>1</enabled><password><enforced></enforced></password></public></files_sharing>
+<checksums><supportedTypes><element>SHA1</element><element>MD5</element></supportedTypes></checksums>
+</capabilities>`,
+	})
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	capabilities, err := c.Capabilities()
+	t.Nil(err)
+	t.True(capabilities.Has("files_sharing"))
+	t.False(capabilities.Has("deck"))
+	t.True(capabilities.Enabled("files", "bigfilechunking"))
+	t.False(capabilities.Enabled("files_sharing", "public", "password", "enforced"))
+	_, ok := capabilities.Value("files_sharing", "public", "password", "enforced")
+	t.True(ok)
+	_, ok = capabilities.Value("files_sharing", "public", "missing")
+	t.False(ok)
+	t.Equal([]string{"SHA1", "MD5"}, capabilities.Values("checksums", "supportedTypes"))
+	t.Equal(0, len(capabilities.Values("checksums", "missing")))
+}
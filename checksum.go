@@ -0,0 +1,220 @@
+package cloud
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/adler32"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrNoChecksum is returned when the server has no checksum stored
+// for a file in any of the supported algorithms.
+var ErrNoChecksum = errors.New("no supported checksum stored on the server")
+
+// checksumAlgorithms are the checksum types used by the server which
+// are supported by the package.
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"MD5":     md5.New,
+	"SHA1":    sha1.New,
+	"SHA256":  sha256.New,
+	"ADLER32": func() hash.Hash { return adler32.New() },
+}
+
+// parseChecksums parses the oc:checksum property, a space separated
+// list of TYPE:digest pairs, keeping the supported types only.
+func parseChecksums(s string) map[string]string {
+	checksums := make(map[string]string)
+	for _, field := range strings.Fields(s) {
+		i := strings.Index(field, ":")
+		if i < 0 {
+			continue
+		}
+		algo := strings.ToUpper(field[:i])
+		if _, ok := checksumAlgorithms[algo]; ok {
+			checksums[algo] = strings.ToLower(field[i+1:])
+		}
+	}
+	return checksums
+}
+
+// ErrChecksumMismatch is returned when a content doesn't match its
+// checksum.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// VerifyUpload compares the checksum the server stored for dest with
+// the one of the local file at localPath. The algorithm is picked
+// among the checksum types advertised by the server capabilities,
+// the preferred one first. Servers which advertise none, like
+// Nextcloud, have every stored checksum among MD5, SHA1, SHA256 and
+// ADLER32 checked. The server stores checksums only when they were
+// supplied on upload: if none is available ErrNoChecksum is
+// returned.
+func (c *Client) VerifyUpload(localPath, dest string) (bool, error) {
+	remote, err := c.remoteChecksums(dest)
+	if err != nil {
+		return false, err
+	}
+	remote, err = c.advertisedChecksum(remote)
+	if err != nil {
+		return false, err
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	return matchChecksums(remote, file)
+}
+
+// remoteChecksums returns the supported checksums stored by the
+// server for the file at p, or ErrNoChecksum.
+func (c *Client) remoteChecksums(p string) (map[string]string, error) {
+	result, err := c.propfind(p, "0", "<oc:checksums/>")
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Responses) == 0 {
+		return nil, ErrNoChecksum
+	}
+	remote := parseChecksums(result.Responses[0].prop().Checksum)
+	if len(remote) == 0 {
+		return nil, ErrNoChecksum
+	}
+	return remote, nil
+}
+
+// advertisedChecksum returns the checksum to verify among checksums:
+// the one of the preferred type advertised by the server
+// capabilities, or else of the first advertised type available. If
+// the server advertises no type, all the checksums are returned.
+func (c *Client) advertisedChecksum(checksums map[string]string) (map[string]string, error) {
+	capabilities, err := c.Capabilities()
+	if err != nil {
+		return nil, err
+	}
+	types := capabilities.Values("checksums", "supportedTypes")
+	if len(types) == 0 {
+		return checksums, nil
+	}
+	if preferred, ok := capabilities.Value("checksums", "preferredUploadType"); ok {
+		types = append([]string{preferred}, types...)
+	}
+	for _, algo := range types {
+		algo = strings.ToUpper(algo)
+		if sum, ok := checksums[algo]; ok {
+			return map[string]string{algo: sum}, nil
+		}
+	}
+	return nil, ErrNoChecksum
+}
+
+// matchChecksums reports whether the content read from r matches all
+// the given checksums, which must be of supported algorithms.
+func matchChecksums(checksums map[string]string, r io.Reader) (bool, error) {
+	hashes := make(map[string]hash.Hash)
+	var writers []io.Writer
+	for algo := range checksums {
+		h := checksumAlgorithms[algo]()
+		hashes[algo] = h
+		writers = append(writers, h)
+	}
+	if _, err := io.Copy(io.MultiWriter(writers...), r); err != nil {
+		return false, err
+	}
+
+	for algo, h := range hashes {
+		if hex.EncodeToString(h.Sum(nil)) != checksums[algo] {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// UploadWithChecksum uploads src to dest along with its checksum
+// computed with algo, one of MD5, SHA1, SHA256 and ADLER32. The
+// server verifies the content it received against the checksum and
+// stores it; if they don't match, the upload is rejected and
+// ErrChecksumMismatch is returned.
+func (c *Client) UploadWithChecksum(dest string, src []byte, algo string) error {
+	algo = strings.ToUpper(algo)
+	newHash, ok := checksumAlgorithms[algo]
+	if !ok {
+		return fmt.Errorf("unsupported checksum algorithm %s", algo)
+	}
+	h := newHash()
+	h.Write(src)
+
+	req, err := c.newWebDavRequest("PUT", dest, bytes.NewReader(src))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("OC-Checksum", algo+":"+hex.EncodeToString(h.Sum(nil)))
+
+	start := time.Now()
+	err = c.sendUpload(req, checkChecksumMismatch)
+	c.emit(OpUpload, dest, int64(len(src)), start, err)
+	return err
+}
+
+// sabreBadRequest is the exception class of a generic Bad Request.
+const sabreBadRequest = `Sabre\DAV\Exception\BadRequest`
+
+// checkChecksumMismatch returns ErrChecksumMismatch when the server
+// rejected an upload carrying OC-Checksum because the content doesn't
+// match it, which is reported as a generic Bad Request: its message
+// may be localized. Failures with a more specific exception class,
+// e.g. an invalid file name, are returned as an *Error.
+func checkChecksumMismatch(resp *http.Response) error {
+	if resp.StatusCode != http.StatusBadRequest {
+		return nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	error := Error{}
+	if xml.Unmarshal(body, &error) != nil || error.Exception == "" || error.Exception == sabreBadRequest {
+		return ErrChecksumMismatch
+	}
+	return &error
+}
+
+// DownloadVerified is like Download but verifies the content against
+// the checksums stored by the server, returning ErrChecksumMismatch
+// if they don't match. If the server has no checksum for the file,
+// ErrNoChecksum is returned.
+func (c *Client) DownloadVerified(path string) ([]byte, error) {
+	remote, err := c.remoteChecksums(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.Download(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := matchChecksums(remote, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrChecksumMismatch
+	}
+	return data, nil
+}
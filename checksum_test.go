@@ -0,0 +1,149 @@
+package cloud
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+)
+
+func (t *testSuite) TestVerifyUpload() {
+	checksums := "SHA1:a0b65939670bc2c010f4d5d6a0b3e4e4590fb92b MD5:8ddd8be4b179a529afa5f2ffae4b9858"
+	capabilities := ""
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ocs/v2.php/cloud/capabilities" {
+			fmt.Fprint(w, capabilitiesXML(capabilities))
+			return
+		}
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprintf(w, `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns">
+ <d:response>
+  <d:href>%s</d:href>
+  <d:propstat><d:prop><oc:checksums><oc:checksum>%s</oc:checksum></oc:checksums></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+</d:multistatus>`, r.URL.Path, checksums)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	ok, err := c.VerifyUpload(filepath.Join(testDir, "test.txt"), "Test/test.txt")
+	t.Nil(err)
+	t.True(ok)
+
+	checksums = "SHA1:0000000000000000000000000000000000000000"
+	ok, err = c.VerifyUpload(filepath.Join(testDir, "test.txt"), "Test/test.txt")
+	t.Nil(err)
+	t.False(ok)
+
+	checksums = "SHA1:a0b65939670bc2c010f4d5d6a0b3e4e4590fb92b MD5:00000000000000000000000000000000"
+	ok, err = c.VerifyUpload(filepath.Join(testDir, "test.txt"), "Test/test.txt")
+	t.Nil(err)
+	t.False(ok)
+
+	// Only the advertised type is checked, the preferred one first.
+	capabilities = `<checksums><supportedTypes><element>MD5</element><element>SHA1</element></supportedTypes><preferredUploadType>SHA1</preferredUploadType></checksums>`
+	ok, err = c.VerifyUpload(filepath.Join(testDir, "test.txt"), "Test/test.txt")
+	t.Nil(err)
+	t.True(ok)
+
+	capabilities = `<checksums><supportedTypes><element>ADLER32</element></supportedTypes></checksums>`
+	_, err = c.VerifyUpload(filepath.Join(testDir, "test.txt"), "Test/test.txt")
+	t.Equal(ErrNoChecksum, err)
+
+	checksums = ""
+	_, err = c.VerifyUpload(filepath.Join(testDir, "test.txt"), "Test/test.txt")
+	t.Equal(ErrNoChecksum, err)
+}
+
+func (t *testSuite) TestUploadWithChecksum() {
+	var content, checksum string
+	// corrupt alters the content received by the server, as a
+	// faulty network would.
+	corrupt := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "PUT":
+			data, _ := ioutil.ReadAll(r.Body)
+			if corrupt {
+				data[0] ^= 1
+			}
+			header := r.Header.Get("OC-Checksum")
+			i := strings.Index(header, ":")
+			h := checksumAlgorithms[header[:i]]()
+			h.Write(data)
+			if hex.EncodeToString(h.Sum(nil)) != header[i+1:] {
+				// The message is localized.
+				sabreError(w, http.StatusBadRequest, `Sabre\DAV\Exception\BadRequest`, "Die berechnete Prüfsumme stimmt nicht überein.")
+				return
+			}
+			content, checksum = string(data), header
+			w.WriteHeader(http.StatusCreated)
+		case "GET":
+			fmt.Fprint(w, content)
+		case "PROPFIND":
+			w.WriteHeader(http.StatusMultiStatus)
+			fmt.Fprintf(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns"><d:response><d:href>%s</d:href><d:propstat><d:prop><d:resourcetype/><oc:checksums><oc:checksum>%s</oc:checksum></oc:checksums></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response></d:multistatus>`, r.URL.Path, checksum)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	t.Nil(c.UploadWithChecksum("Test/test.txt", []byte("Hello World!\n"), "sha1"))
+	t.Equal("SHA1:a0b65939670bc2c010f4d5d6a0b3e4e4590fb92b", checksum)
+
+	info, err := c.Stat("Test/test.txt")
+	t.Nil(err)
+	t.Equal(map[string]string{"SHA1": "a0b65939670bc2c010f4d5d6a0b3e4e4590fb92b"}, info.Checksums)
+
+	data, err := c.DownloadVerified("Test/test.txt")
+	t.Nil(err)
+	t.Equal("Hello World!\n", string(data))
+
+	// The content changed on the server behind its checksum.
+	content = "Hello World?\n"
+	_, err = c.DownloadVerified("Test/test.txt")
+	t.Equal(ErrChecksumMismatch, err)
+
+	corrupt = true
+	t.Equal(ErrChecksumMismatch, c.UploadWithChecksum("Test/test.txt", []byte("Hello World!\n"), "MD5"))
+	t.NotNil(c.UploadWithChecksum("Test/test.txt", []byte("Hello World!\n"), "CRC32"))
+}
+
+func (t *testSuite) TestUploadWithChecksumBadRequest() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sabreError(w, http.StatusBadRequest, `OCA\DAV\Connector\Sabre\Exception\InvalidPath`, "Invalid file name")
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	// Only a checksum mismatch is reported as such.
+	err = c.UploadWithChecksum("Test/test.txt", []byte("Hello World!\n"), "SHA1")
+	exception, ok := err.(*Error)
+	t.True(ok)
+	if ok {
+		t.Equal("Invalid file name", exception.Message)
+	}
+}
+
+// capabilitiesXML returns an OCS capabilities response advertising
+// the given capabilities.
+func capabilitiesXML(capabilities string) string {
+	return fmt.Sprintf(`<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode><message>OK</message></meta><data><capabilities>%s</capabilities></data></ocs>`, capabilities)
+}
+
+// sabreError replies with the error document of a Sabre exception.
+func sabreError(w http.ResponseWriter, status int, exception, message string) {
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>
+<d:error xmlns:d="DAV:" xmlns:s="http://sabredav.org/ns"><s:exception>%s</s:exception><s:message>%s</s:message></d:error>`, exception, message)
+}
@@ -0,0 +1,287 @@
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+)
+
+// UploadChunked uploads the content read from r to dest in chunks of
+// chunkSize bytes, using the chunked upload protocol of Nextcloud.
+// This works around the limit on the size of a single request, and
+// only chunkSize bytes are held in memory at once.
+//
+// The chunks are staged in a folder derived from dest, so a call
+// following an interrupted upload to the same dest skips the chunks
+// already on the server, once checked that their content matches:
+// downloading a chunk is usually faster than uploading it. Chunks
+// left by the upload of a different content are replaced. If the
+// upload fails, the staged chunks are removed.
+//
+// A chunkSize of zero or less picks the size suited to the limits
+// advertised by the server, see UploadLimits.ChunkSize.
+func (c *Client) UploadChunked(dest string, r io.Reader, chunkSize int64) error {
+	if chunkSize <= 0 {
+		limits, err := c.UploadLimits()
+		if err != nil {
+			return err
+		}
+		chunkSize = limits.ChunkSize()
+	}
+
+	start := time.Now()
+	counter := &countingReader{r: r}
+	err := c.uploadChunked(dest, counter, chunkSize)
+	c.emit(OpUpload, dest, counter.count(), start, err)
+	return err
+}
+
+// uploadChunked uploads the content read from r to dest in chunks of
+// chunkSize bytes, see UploadChunked.
+func (c *Client) uploadChunked(dest string, r io.Reader, chunkSize int64) error {
+	dir := c.chunkedUploadDir(dest)
+	uploaded, err := c.startChunkedUpload(dir, dest)
+	if err != nil {
+		return err
+	}
+
+	err = c.uploadChunks(dir, dest, r, chunkSize, uploaded)
+	if err != nil {
+		// The error of the upload matters more than the one of
+		// the cleanup.
+		c.deleteDav(context.Background(), dir)
+		return err
+	}
+
+	return nil
+}
+
+// chunkedUploadDir returns the folder staging the chunks of an
+// upload to dest.
+func (c *Client) chunkedUploadDir(dest string) string {
+	sum := sha1.Sum([]byte(path.Clean("/" + dest)))
+	return path.Join("remote.php/dav/uploads", c.Username, "cloud-"+hex.EncodeToString(sum[:]))
+}
+
+// startChunkedUpload creates the staging folder dir and returns the
+// size of the chunks it already contains, by name.
+func (c *Client) startChunkedUpload(dir, dest string) (map[string]int64, error) {
+	req, err := c.newRequest("MKCOL", dir, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Destination", c.resolve(path.Join(c.filesRoot(), dest)))
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	uploaded := make(map[string]int64)
+	switch resp.StatusCode {
+	case http.StatusCreated:
+		return uploaded, nil
+	case http.StatusMethodNotAllowed:
+		// The folder exists, left by an interrupted upload.
+	default:
+		return nil, newStatusError(resp)
+	}
+
+	result, err := c.davPropfind(dir, "1", "<d:getcontentlength/>")
+	if err != nil {
+		return nil, err
+	}
+	for i := range result.Responses {
+		p, err := c.hrefPath(result.Responses[i].Href, dir)
+		if err != nil {
+			return nil, err
+		}
+		if p != "/" {
+			uploaded[path.Base(p)] = result.Responses[i].prop().ContentLength
+		}
+	}
+
+	return uploaded, nil
+}
+
+// uploadChunks sends the chunks read from r to dir, skipping the
+// ones found in uploaded with the same content, then assembles them
+// into dest.
+func (c *Client) uploadChunks(dir, dest string, r io.Reader, chunkSize int64, uploaded map[string]int64) error {
+	buf := make([]byte, chunkSize)
+	var total int64
+	for i := 1; ; i++ {
+		n, err := io.ReadFull(r, buf)
+		// An empty content is still sent as a single chunk.
+		if err == io.EOF && i > 1 {
+			break
+		}
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		total += int64(n)
+
+		// Chunk names are zero-padded so that they sort in
+		// order.
+		name := fmt.Sprintf("%05d", i)
+		size, ok := uploaded[name]
+		delete(uploaded, name)
+		if !ok || size != int64(n) || !c.chunkMatches(path.Join(dir, name), buf[:n]) {
+			req, err := c.newRequest("PUT", path.Join(dir, name), bytes.NewReader(buf[:n]))
+			if err != nil {
+				return err
+			}
+			if err := c.sendUpload(req, nil); err != nil {
+				return err
+			}
+		}
+
+		if int64(n) < chunkSize {
+			break
+		}
+	}
+
+	// Chunks beyond the end of a longer content would be
+	// assembled too.
+	for name := range uploaded {
+		if err := c.deleteDav(context.Background(), path.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+
+	return c.assembleChunks(dir, dest, total)
+}
+
+// chunkMatches reports whether the chunk staged at davPath holds
+// data.
+func (c *Client) chunkMatches(davPath string, data []byte) bool {
+	req, err := c.newRequest("GET", davPath, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	staged, err := ioutil.ReadAll(io.LimitReader(resp.Body, int64(len(data))+1))
+	return err == nil && bytes.Equal(staged, data)
+}
+
+// assembleChunks moves the chunks staged in dir, total bytes in all,
+// to dest.
+func (c *Client) assembleChunks(dir, dest string, total int64) error {
+	req, err := c.newRequest("MOVE", path.Join(dir, ".file"), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Destination", c.resolve(path.Join(c.filesRoot(), dest)))
+	req.Header.Set("OC-Total-Length", strconv.FormatInt(total, 10))
+
+	return c.sendUpload(req, nil)
+}
+
+// Append appends data to the file at p, which is created if it
+// doesn't exist. WebDAV can't write at an offset and chunks can't
+// refer to content already on the server, so the current content is
+// read back, after a PROPFIND of its size and ETag, and uploaded as
+// the first chunk of a chunked upload whose second chunk is data.
+//
+// Append is not atomic: a write by another client between the
+// PROPFIND and the final MOVE assembling the chunks is lost. Writes
+// happening before the content is read back make Append fail with
+// ErrPreconditionFailed instead.
+func (c *Client) Append(p string, data []byte) error {
+	info, err := c.Stat(p)
+	if err == ErrNotFound {
+		return c.Upload(data, p)
+	}
+	if err != nil {
+		return err
+	}
+	if info.IsDir {
+		return fmt.Errorf("%s is a folder", p)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	// The current content is uploaded again along with data.
+	start := time.Now()
+	err = c.appendTo(p, info, data)
+	c.emit(OpUpload, p, info.Size+int64(len(data)), start, err)
+	return err
+}
+
+// appendTo appends data to the file at p, whose size and ETag are
+// given by info, see Append.
+func (c *Client) appendTo(p string, info *FileInfo, data []byte) error {
+	req, err := c.newWebDavRequest("GET", p, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("If-Match", quoteETag(info.ETag))
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusPreconditionFailed:
+		return ErrPreconditionFailed
+	case resp.StatusCode != http.StatusOK:
+		return newStatusError(resp)
+	}
+
+	// Leftovers of an interrupted upload to p would be assembled
+	// along with the new chunks.
+	dir := c.chunkedUploadDir(p)
+	if err := c.deleteDav(context.Background(), dir); err != nil {
+		return err
+	}
+	if _, err := c.startChunkedUpload(dir, p); err != nil {
+		return err
+	}
+
+	err = c.appendChunks(dir, p, io.LimitReader(resp.Body, info.Size), info.Size, data)
+	if err != nil {
+		c.deleteDav(context.Background(), dir)
+		return err
+	}
+	return nil
+}
+
+// appendChunks sends the size bytes read from current and data as
+// two chunks to dir, then assembles them into dest.
+func (c *Client) appendChunks(dir, dest string, current io.Reader, size int64, data []byte) error {
+	chunks := []struct {
+		r    io.Reader
+		size int64
+	}{
+		{current, size},
+		{bytes.NewReader(data), int64(len(data))},
+	}
+	for i, chunk := range chunks {
+		req, err := c.newRequest("PUT", path.Join(dir, fmt.Sprintf("%05d", i+1)), chunk.r)
+		if err != nil {
+			return err
+		}
+		req.ContentLength = chunk.size
+		if err := c.sendUpload(req, nil); err != nil {
+			return err
+		}
+	}
+	return c.assembleChunks(dir, dest, size+int64(len(data)))
+}
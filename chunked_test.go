@@ -0,0 +1,204 @@
+package cloud
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// chunkServer implements the subset of the chunked upload protocol
+// used by UploadChunked, for a single upload.
+type chunkServer struct {
+	*httptest.Server
+
+	dir    bool
+	chunks map[string][]byte
+	puts   []string
+	failOn string
+	files  map[string]string
+
+	// destination is the Destination header of the MKCOL creating
+	// the staging folder.
+	destination string
+
+	// capabilities are the capabilities advertised by the server.
+	capabilities string
+}
+
+func newChunkServer() *chunkServer {
+	s := &chunkServer{
+		chunks: make(map[string][]byte),
+		files:  make(map[string]string),
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+func (s *chunkServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/ocs/v2.php/cloud/capabilities" {
+		fmt.Fprint(w, capabilitiesXML(s.capabilities))
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, "/remote.php/dav/uploads/admin/cloud-") {
+		http.NotFound(w, r)
+		return
+	}
+	dir := strings.HasSuffix(path.Dir(r.URL.Path), "/admin")
+	name := path.Base(r.URL.Path)
+
+	switch {
+	case r.Method == "MKCOL" && dir:
+		if s.dir {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		s.dir = true
+		s.destination = r.Header.Get("Destination")
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == "PROPFIND" && dir:
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprintf(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:"><d:response><d:href>%s/</d:href><d:propstat><d:prop/><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`, r.URL.Path)
+		for name, data := range s.chunks {
+			fmt.Fprintf(w, `<d:response><d:href>%s/%s</d:href><d:propstat><d:prop><d:getcontentlength>%d</d:getcontentlength></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`, r.URL.Path, name, len(data))
+		}
+		fmt.Fprint(w, `</d:multistatus>`)
+	case r.Method == "DELETE" && dir:
+		s.dir = false
+		s.chunks = make(map[string][]byte)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "GET" && s.dir:
+		data, ok := s.chunks[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	case r.Method == "DELETE" && s.dir:
+		delete(s.chunks, name)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "PUT" && s.dir:
+		if name == s.failOn {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		s.chunks[name] = data
+		s.puts = append(s.puts, name)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == "MOVE" && s.dir && name == ".file":
+		var names []string
+		for name := range s.chunks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var content string
+		for _, name := range names {
+			content += string(s.chunks[name])
+		}
+		if r.Header.Get("OC-Total-Length") != fmt.Sprint(len(content)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.files[r.Header.Get("Destination")] = content
+		s.dir = false
+		s.chunks = make(map[string][]byte)
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (t *testSuite) TestUploadChunked() {
+	s := newChunkServer()
+	defer s.Close()
+
+	c, err := Dial(s.URL+"/", "admin", "password")
+	t.Nil(err)
+	dest := s.URL + "/remote.php/dav/files/admin/Test/big%20file.txt"
+
+	err = c.UploadChunked("Test/big file.txt", strings.NewReader("Hello World!"), 5)
+	t.Nil(err)
+	t.Equal([]string{"00001", "00002", "00003"}, s.puts)
+	t.Equal("Hello World!", s.files[dest])
+	t.Equal(dest, s.destination)
+	t.False(s.dir)
+
+	// Resume an upload whose first chunk is already on the server.
+	s.puts = nil
+	s.dir = true
+	s.chunks["00001"] = []byte("Hello")
+	err = c.UploadChunked("Test/big file.txt", strings.NewReader("Hello World!"), 5)
+	t.Nil(err)
+	t.Equal([]string{"00002", "00003"}, s.puts)
+	t.Equal("Hello World!", s.files[dest])
+
+	// Chunks left by the upload of another content are replaced,
+	// even if their size matches.
+	s.puts = nil
+	s.dir = true
+	s.chunks["00001"] = []byte("Howdy")
+	s.chunks["00002"] = []byte(" Worl")
+	s.chunks["00004"] = []byte("stale")
+	err = c.UploadChunked("Test/big file.txt", strings.NewReader("Hello World!"), 5)
+	t.Nil(err)
+	t.Equal([]string{"00001", "00003"}, s.puts)
+	t.Equal("Hello World!", s.files[dest])
+
+	s.puts = nil
+	s.failOn = "00002"
+	err = c.UploadChunked("Test/big file.txt", strings.NewReader("Hello World!"), 5)
+	t.NotNil(err)
+	t.False(s.dir)
+
+	// The chunk size defaults to the limits of the server.
+	s.puts = nil
+	s.failOn = ""
+	s.capabilities = `<files><upload_max_filesize>4</upload_max_filesize></files>`
+	err = c.UploadChunked("Test/big file.txt", strings.NewReader("Hello World!"), 0)
+	t.Nil(err)
+	t.Equal([]string{"00001", "00002", "00003"}, s.puts)
+	t.Equal("Hello World!", s.files[dest])
+}
+
+func (t *testSuite) TestAppend() {
+	dav := newDavServer()
+	defer dav.Close()
+	dav.dirs["/Test"] = true
+	chunks := newChunkServer()
+	defer chunks.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/remote.php/dav/uploads/") {
+			dav.ServeHTTP(w, r)
+			return
+		}
+		chunks.ServeHTTP(w, r)
+		// Assembled files land in the WebDAV tree.
+		for dest, content := range chunks.files {
+			u, _ := url.Parse(dest)
+			dav.files[strings.TrimPrefix(u.Path, "/remote.php/dav/files/admin")] = []byte(content)
+			delete(chunks.files, dest)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	t.Nil(c.Append("Test/log.txt", []byte("one\n")))
+	t.Equal("one\n", string(dav.files["/Test/log.txt"]))
+	t.Nil(chunks.puts)
+
+	t.Nil(c.Append("Test/log.txt", []byte("two\n")))
+	t.Nil(c.Append("Test/log.txt", []byte("three\n")))
+	t.Equal("one\ntwo\nthree\n", string(dav.files["/Test/log.txt"]))
+	t.Equal([]string{"00001", "00002", "00001", "00002"}, chunks.puts)
+	t.False(chunks.dir)
+
+	t.NotNil(c.Append("Test", []byte("four\n")))
+}
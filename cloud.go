@@ -2,22 +2,120 @@ package cloud
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+// defaultMaxRedirects is the number of redirects followed when
+// Client.MaxRedirects is zero.
+const defaultMaxRedirects = 10
+
+// latencyTimeout bounds the request sent by Latency.
+const latencyTimeout = 10 * time.Second
+
 // A client represents a client connection to a {own|next}cloud
 type Client struct {
 	Url      *url.URL
 	Username string
 	Password string
+
+	// WebDAVRoot is the path of the WebDAV endpoint serving the
+	// files, relative to Url. It defaults to "remote.php/webdav",
+	// the legacy endpoint supported by every server. Modern
+	// Nextcloud servers prefer the endpoint returned by FilesRoot,
+	// which is the one used by chunked uploads, versions and trash,
+	// but it is specific to a user and unknown to old ownCloud
+	// servers.
+	WebDAVRoot string
+
+	// Auth, if non-nil, authenticates the requests instead of
+	// Basic authentication with Username and Password.
+	Auth Authenticator
+
+	// RetryPolicy, if non-nil, makes requests failing with a
+	// transient error be retried.
+	RetryPolicy *RetryPolicy
+
+	// CheckRedirect, if non-nil, is called before following a
+	// redirect and replaces the default policy. It has the same
+	// semantics as http.Client.CheckRedirect.
+	CheckRedirect func(req *http.Request, via []*http.Request) error
+
+	// MaxRedirects is the maximum number of redirects followed by
+	// the default policy. Zero means 10, a negative value disables
+	// redirects and returns the redirect response as is.
+	MaxRedirects int
+
+	// LockRetry, if non-nil, makes uploads wait and retry when
+	// the destination file is locked.
+	LockRetry *LockRetry
+
+	// Events, if non-nil, receives an Event for each upload or
+	// download, whether it succeeded or not. Files read through
+	// FileSystem are not reported. Events are dropped when the
+	// channel is not ready, so a slow consumer never stalls the
+	// transfers.
+	Events chan<- Event
+
+	// HTTPClient, if non-nil, is used to send the requests, e.g. to
+	// set a timeout, a proxy or the TLS configuration of a server
+	// with a self-signed certificate. If its CheckRedirect is nil,
+	// the redirect policy of the Client applies. When HTTPClient is
+	// nil, a client created on the first request is used, whose
+	// transport is shared by all the clients of the package.
+	HTTPClient *http.Client
+
+	// OnRequest, if non-nil, is called after each HTTP request
+	// sent to the server, retries included, e.g. to log them. url
+	// is the resolved URL of the request, without the credentials
+	// it may hold. status is zero when err is not nil.
+	OnRequest func(method, url string, status int, duration time.Duration, err error)
+
+	// OCSFormat is the format requested to the OCS API, used for
+	// shares, group folders and users. It defaults to XML, while
+	// JSON is immune to the XML escaping issues of some servers.
+	OCSFormat OCSFormat
+
+	// DeduplicateShares makes share creation return an existing
+	// share of the same path, type, recipient and permissions
+	// instead of creating a new one. The server has no support for
+	// idempotent requests, so this is what makes retrying a share
+	// creation after a network failure safe.
+	DeduplicateShares bool
+
+	// flavorMu guards flavor and version, which cache the result
+	// of ServerFlavor.
+	flavorMu        sync.Mutex
+	flavor, version string
+
+	// defaultClient is the client used when HTTPClient is nil.
+	defaultClientOnce sync.Once
+	defaultClient     *http.Client
 }
 
+// defaultTransport is the transport of the clients created when
+// Client.HTTPClient is nil. It keeps more idle connections per host
+// than http.DefaultTransport, since requests are usually sent to a
+// single server, often concurrently.
+var defaultTransport = func() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = 16
+	return t
+}()
+
 // Error type encapsulates the returned error messages from the
 // server.
 type Error struct {
@@ -33,30 +131,123 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("Exception: %s, Message: %s", e.Exception, e.Message)
 }
 
-type ShareElement struct {
-	Id  uint   `xml:"id"`
-	Url string `xml:"url"`
+// StatusError is returned when the server replies with a status code
+// outside of the 2xx range and no more specific error is available.
+type StatusError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Method is the method of the request, e.g. "PROPFIND".
+	Method string
+
+	// URL is the resolved URL of the request, without the
+	// credentials it may hold.
+	URL string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+func newStatusError(resp *http.Response) *StatusError {
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		Method:     resp.Request.Method,
+		URL:        redactURL(resp.Request.URL),
+	}
+}
+
+// redactURL returns u without its user information, so that the
+// credentials of the server URL never appear in errors and logs.
+func redactURL(u *url.URL) string {
+	if u.User == nil {
+		return u.String()
+	}
+	redacted := *u
+	redacted.User = nil
+	return redacted.String()
+}
+
+// Share describes a single share as returned by the OCS share API.
+type Share struct {
+	Id                   uint            `xml:"id" json:"id"`
+	ShareType            int             `xml:"share_type" json:"share_type"`
+	UidOwner             string          `xml:"uid_owner" json:"uid_owner"`
+	DisplaynameOwner     string          `xml:"displayname_owner" json:"displayname_owner"`
+	Permissions          int             `xml:"permissions" json:"permissions"`
+	Path                 string          `xml:"path" json:"path"`
+	ItemType             string          `xml:"item_type" json:"item_type"`
+	ShareWith            string          `xml:"share_with" json:"share_with"`
+	ShareWithDisplayname string          `xml:"share_with_displayname" json:"share_with_displayname"`
+	Token                string          `xml:"token" json:"token"`
+	Url                  string          `xml:"url" json:"url"`
+	Expiration           string          `xml:"expiration" json:"expiration"`
+	Note                 string          `xml:"note" json:"note"`
+	Attributes           ShareAttributes `xml:"attributes" json:"attributes"`
+
+	// Remote is the server a federated share comes from, as set
+	// by ListPendingShares, or goes to, as set by ListShares and
+	// ShareWithRemote.
+	Remote string `xml:"remote" json:"remote"`
+}
+
+// UnmarshalJSON decodes a share of a JSON response, in which the id
+// is a string.
+func (s *Share) UnmarshalJSON(data []byte) error {
+	type share Share
+	aux := struct {
+		share
+		Id ocsUint `json:"id"`
+	}{}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*s = Share(aux.share)
+	s.Id = uint(aux.Id)
+	return nil
+}
+
+// ExpireDate returns the date at which the share expires, or the
+// zero time if it doesn't expire.
+func (s *Share) ExpireDate() time.Time {
+	expiry, err := time.ParseInLocation("2006-01-02 15:04:05", s.Expiration, time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+	return expiry
 }
 
+// ShareElement is the former name of Share.
+type ShareElement = Share
+
+// ShareResult is the response of the share API. A single share, as
+// returned when a share is created, is found both in Id and Url and
+// as the only item of Elements. A list of shares is found in
+// Elements, and in Id and Url too if it holds a single share.
 type ShareResult struct {
-	XMLName    xml.Name       `xml:"ocs"`
-	Status     string         `xml:"meta>status"`
-	StatusCode uint           `xml:"meta>statuscode"`
-	Message    string         `xml:"meta>message"`
-	Id         uint           `xml:"data>id"`
-	Url        string         `xml:"data>url"`
-	Elements   []ShareElement `xml:"data>element"`
+	XMLName    xml.Name `xml:"ocs"`
+	Status     string   `xml:"meta>status"`
+	StatusCode uint     `xml:"meta>statuscode"`
+	Message    string   `xml:"meta>message"`
+	Id         uint     `xml:"data>id"`
+	Url        string   `xml:"data>url"`
+	Elements   []Share  `xml:"data>element"`
 }
 
 // Dial connects to an {own|next}Cloud instance at the specified
 // address using the given credentials.
 func Dial(host, username, password string) (*Client, error) {
-	url, err := url.Parse(host)
+	u, err := url.Parse(host)
 	if err != nil {
-		return nil, err
+		// The error of url.Parse quotes host, which may hold
+		// credentials.
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("invalid server URL: %w", err)
 	}
 	return &Client{
-		Url:      url,
+		Url:      u,
 		Username: username,
 		Password: password,
 	}, nil
@@ -64,21 +255,63 @@ func Dial(host, username, password string) (*Client, error) {
 
 // Mkdir creates a new directory on the cloud with the specified name.
 func (c *Client) Mkdir(path string) error {
-	_, err := c.sendWebDavRequest("MKCOL", path, nil)
+	return c.MkdirContext(context.Background(), path)
+}
+
+// MkdirContext is like Mkdir but aborts the request when ctx is
+// done.
+func (c *Client) MkdirContext(ctx context.Context, path string) error {
+	_, err := c.sendWebDavRequest(ctx, "MKCOL", path, nil)
 	return err
+}
 
+// MkdirAll creates the given folder along with any missing parent.
+// Unlike Mkdir, it doesn't fail if the folder already exists.
+func (c *Client) MkdirAll(dir string) error {
+	var current string
+	for _, name := range strings.Split(strings.Trim(dir, "/"), "/") {
+		if name == "" {
+			continue
+		}
+		current = path.Join(current, name)
+		if err := c.mkdirExist(current); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// Delete removes the specified folder from the cloud.
+// Delete removes the specified folder from the cloud. The root
+// folder can't be deleted: ErrRootPath is returned.
 func (c *Client) Delete(path string) error {
-	_, err := c.sendWebDavRequest("DELETE", path, nil)
+	return c.DeleteContext(context.Background(), path)
+}
+
+// DeleteContext is like Delete but aborts the request when ctx is
+// done.
+func (c *Client) DeleteContext(ctx context.Context, path string) error {
+	if isRoot(path) {
+		return ErrRootPath
+	}
+	_, err := c.sendWebDavRequest(ctx, "DELETE", path, nil)
 	return err
 }
 
 // Upload uploads the specified source to the specified destination
 // path on the cloud.
 func (c *Client) Upload(src []byte, dest string) error {
-	_, err := c.sendWebDavRequest("PUT", dest, src)
+	return c.UploadContext(context.Background(), src, dest)
+}
+
+// UploadContext is like Upload but aborts the request, or the wait
+// for a locked destination, when ctx is done.
+func (c *Client) UploadContext(ctx context.Context, src []byte, dest string) error {
+	start := time.Now()
+	err := c.retryLocked(ctx, func() error {
+		_, err := c.sendWebDavRequest(ctx, "PUT", dest, src)
+		return err
+	})
+	c.emit(OpUpload, dest, int64(len(src)), start, err)
 	return err
 }
 
@@ -89,200 +322,635 @@ func (c *Client) UploadDir(src string, dest string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	for _, file := range files {
-		data, err := ioutil.ReadFile(file)
-		if err != nil {
-			return nil, err
-		}
-		err = c.Upload(data, filepath.Join(dest, filepath.Base(file)))
-		if err != nil {
-			return nil, err
+	return c.UploadFiles(files, dest)
+}
+
+// UploadFiles uploads the given local files to the folder dest,
+// keeping their base names. It returns files or the first error,
+// like UploadDir.
+func (c *Client) UploadFiles(files []string, dest string) ([]string, error) {
+	results, _ := c.uploadFiles(context.Background(), files, dest, 1, true)
+	for _, result := range results {
+		if result.Err != nil {
+			return nil, result.Err
 		}
 	}
-
 	return files, nil
 }
 
-// Download downloads a file from the specified path.
-func (c *Client) Download(path string) ([]byte, error) {
-	return c.sendWebDavRequest("GET", path, nil)
+// UploadResult is the outcome of the upload of a single file.
+type UploadResult struct {
+	// Src is the path of the local file.
+	Src string
+
+	// Dest is the remote path of the file.
+	Dest string
+
+	// Err is the error of the upload, nil on success.
+	Err error
 }
 
-func (c *Client) Exists(path string) bool {
-	_, err := c.sendWebDavRequest("PROPFIND", path, nil)
-	return err == nil
+// UploadDirResult is like UploadDir but goes on when a file fails to
+// upload and returns the outcome of each file, in the order of the
+// glob matches. If failFast is true, it stops at the first failure
+// instead, which is then the last result. The returned error is only
+// about the glob pattern.
+func (c *Client) UploadDirResult(src string, dest string, failFast bool) ([]UploadResult, error) {
+	files, err := filepath.Glob(src)
+	if err != nil {
+		return nil, err
+	}
+	results, _ := c.uploadFiles(context.Background(), files, dest, 1, failFast)
+	return results, nil
 }
 
-func (c *Client) CreateGroupFolder(mountPoint string) (*ShareResult, error) {
-	return c.sendAppsRequest("POST", "groupfolders/folders", fmt.Sprintf("mountpoint=%s", mountPoint))
+// uploadFiles uploads the given local files to the folder dest, up
+// to workers at once, and returns the outcome of each of them in the
+// order of files, along with the summary of the batch. If failFast is
+// true, the first failure cancels the uploads not yet completed, and
+// the files never started are left out of the results.
+func (c *Client) uploadFiles(ctx context.Context, files []string, dest string, workers int, failFast bool) ([]UploadResult, BatchSummary) {
+	b := NewBatch(ctx, workers)
+	results := make([]UploadResult, len(files))
+	started := make([]bool, len(files))
+	for i, file := range files {
+		i, file := i, file
+		results[i] = UploadResult{Src: file, Dest: path.Join(dest, filepath.Base(file))}
+		started[i] = b.Go(file, func(ctx context.Context) error {
+			err := c.uploadDirFile(ctx, file, dest)
+			results[i].Err = err
+			if err != nil && failFast {
+				b.Cancel()
+			}
+			return err
+		})
+	}
+	summary := b.Wait()
+
+	var done []UploadResult
+	for i := range results {
+		if started[i] {
+			done = append(done, results[i])
+		}
+	}
+	return done, summary
 }
 
-func (c *Client) AddGroupToGroupFolder(group string, folderId uint) (*ShareResult, error) {
-	return c.sendAppsRequest("POST", fmt.Sprintf("groupfolders/folders/%d/groups", folderId), fmt.Sprintf("group=%s", group))
+// UploadDirConcurrent is like UploadDir but uploads up to workers
+// files at once, which is much faster for many small files over a
+// high latency connection. The returned paths are in the same order
+// as for UploadDir. The first failure cancels the uploads not yet
+// completed and is returned.
+func (c *Client) UploadDirConcurrent(src string, dest string, workers int) ([]string, error) {
+	return c.UploadDirConcurrentContext(context.Background(), src, dest, workers)
 }
 
-func (c *Client) SetGroupPermissionsForGroupFolder(permissions int, group string, folderId uint) (*ShareResult, error) {
-	return c.sendAppsRequest("POST", fmt.Sprintf("apps/groupfolders/folders/%d/groups/%s", folderId, group), fmt.Sprintf("permissions=%d", permissions))
+// UploadDirConcurrentContext is like UploadDirConcurrent but stops
+// the uploads when ctx is done, returning ctx.Err().
+func (c *Client) UploadDirConcurrentContext(ctx context.Context, src string, dest string, workers int) ([]string, error) {
+	files, err := filepath.Glob(src)
+	if err != nil {
+		return nil, err
+	}
+
+	_, summary := c.uploadFiles(ctx, files, dest, workers, true)
+	if err := summary.Err(); err != nil {
+		return nil, err
+	}
+	if len(summary.Cancelled) > 0 {
+		return nil, ctx.Err()
+	}
+	return files, nil
 }
 
-func (c *Client) CreateShare(path string, shareType int, publicUpload string, permissions int) (*ShareResult, error) {
-	return c.sendOCSRequest("POST", "shares", fmt.Sprintf("path=%s&shareType=%d&publicUpload=%s&permissions=%d", path, shareType, publicUpload, permissions))
+// uploadDirFile uploads the local file to the folder dest, keeping
+// its base name.
+func (c *Client) uploadDirFile(ctx context.Context, file string, dest string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return c.UploadContext(ctx, data, path.Join(dest, filepath.Base(file)))
 }
 
-func (c *Client) GetShare(path string) (*ShareResult, error) {
-	return c.sendOCSRequest("GET", fmt.Sprintf("shares?path=%s", path), "")
+// UploadTree uploads the content of localDir to remoteDir,
+// subfolders included, creating the missing remote folders. It
+// returns the remote paths of the uploaded files and folders.
+func (c *Client) UploadTree(localDir, remoteDir string) ([]string, error) {
+	if err := c.MkdirAll(remoteDir); err != nil {
+		return nil, err
+	}
+
+	var uploaded []string
+	err := filepath.WalkDir(localDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(localDir, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		remote := path.Join(remoteDir, filepath.ToSlash(rel))
+
+		if d.IsDir() {
+			err = c.mkdirExist(remote)
+		} else {
+			err = c.UploadFile(p, remote)
+		}
+		if err != nil {
+			return err
+		}
+		uploaded = append(uploaded, remote)
+		return nil
+	})
+
+	return uploaded, err
 }
 
-func (c *Client) DeleteShare(id uint) (*ShareResult, error) {
-	return c.sendOCSRequest("DELETE", fmt.Sprintf("shares/%d", id), "")
+// Download downloads a file from the specified path.
+func (c *Client) Download(path string) ([]byte, error) {
+	return c.DownloadContext(context.Background(), path)
 }
 
-func (c *Client) CreateFileDropShare(path string) (*ShareResult, error) {
-	result, err := c.CreateShare(path, 3, "true", 4)
+// DownloadContext is like Download but aborts the transfer when ctx
+// is done, returning ctx.Err().
+func (c *Client) DownloadContext(ctx context.Context, path string) ([]byte, error) {
+	start := time.Now()
+	data, err := c.sendWebDavRequest(ctx, "GET", path, nil)
+	c.emit(OpDownload, path, int64(len(data)), start, err)
+	return data, err
+}
+
+// DownloadByID downloads the file with the given server-side id, as
+// found in activities or shares, without knowing its path.
+func (c *Client) DownloadByID(fileId string) ([]byte, error) {
+	path, err := c.filePath(fileId)
 	if err != nil {
 		return nil, err
 	}
-	id := result.Id
-	return c.sendOCSRequest("PUT", fmt.Sprintf("shares/%d", id), "permissions=4")
+	return c.Download(path)
 }
 
-func (c *Client) CreateReadOnlyShare(path string) (*ShareResult, error) {
-	result, err := c.CreateShare(path, 3, "true", 4)
+// Exists reports whether a file or folder exists at path. An error
+// is returned when the server can't tell, e.g. because it is not
+// reachable or the credentials are wrong.
+func (c *Client) Exists(path string) (bool, error) {
+	return c.ExistsContext(context.Background(), path)
+}
+
+// ExistsContext is like Exists but aborts the request when ctx is
+// done.
+func (c *Client) ExistsContext(ctx context.Context, path string) (bool, error) {
+	req, err := c.newWebDavRequestContext(ctx, "PROPFIND", path, nil)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
-	id := result.Id
-	return c.sendOCSRequest("PUT", fmt.Sprintf("shares/%d", id), "permissions=1")
-}
+	req.Header.Set("Depth", "0")
 
-func (c *Client) sendWebDavRequest(request string, path string, data []byte) ([]byte, error) {
-	// Create the https request
+	resp, err := c.do(req)
+	if err != nil {
+		return false, contextErr(ctx, err)
+	}
+	resp.Body.Close()
 
-	webdavPath := filepath.Join("remote.php/webdav", path)
+	switch resp.StatusCode {
+	case http.StatusMultiStatus, http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	}
+	return false, newStatusError(resp)
+}
 
-	folderUrl, err := url.Parse(webdavPath)
+// Latency measures the round-trip time of a lightweight PROPFIND on
+// the root folder. The request is sent only once and times out after
+// latencyTimeout, so the result reflects a single exchange with the
+// server.
+func (c *Client) Latency() (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), latencyTimeout)
+	defer cancel()
+
+	req, err := c.newWebDavRequestContext(ctx, "PROPFIND", "", nil)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
+	req.Header.Set("Depth", "0")
 
-	client := &http.Client{}
-	req, err := http.NewRequest(request, c.Url.ResolveReference(folderUrl).String(), bytes.NewReader(data))
+	// send rather than do: a retry would be measured too.
+	start := time.Now()
+	resp, err := c.send(req)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
+	elapsed := time.Since(start)
+	resp.Body.Close()
 
-	req.SetBasicAuth(c.Username, c.Password)
+	if resp.StatusCode != http.StatusMultiStatus {
+		return elapsed, newStatusError(resp)
+	}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+	return elapsed, nil
+}
+
+func (c *Client) CreateGroupFolder(mountPoint string) (*ShareResult, error) {
+	return c.CreateGroupFolderContext(context.Background(), mountPoint)
+}
+
+// CreateGroupFolderContext is like CreateGroupFolder but aborts the
+// request when ctx is done.
+func (c *Client) CreateGroupFolderContext(ctx context.Context, mountPoint string) (*ShareResult, error) {
+	return c.sendGroupFoldersRequest(ctx, "POST", "folders", url.Values{"mountpoint": {mountPoint}}.Encode())
+}
+
+func (c *Client) AddGroupToGroupFolder(group string, folderId uint) (*ShareResult, error) {
+	return c.AddGroupToGroupFolderContext(context.Background(), group, folderId)
+}
+
+// AddGroupToGroupFolderContext is like AddGroupToGroupFolder but
+// aborts the request when ctx is done.
+func (c *Client) AddGroupToGroupFolderContext(ctx context.Context, group string, folderId uint) (*ShareResult, error) {
+	return c.sendGroupFoldersRequest(ctx, "POST", fmt.Sprintf("folders/%d/groups", folderId), url.Values{"group": {group}}.Encode())
+}
+
+// SetGroupPermissionsForGroupFolder sets the permissions granted to
+// group on the given group folder, e.g.
+// PermissionRead|PermissionUpdate.
+func (c *Client) SetGroupPermissionsForGroupFolder(permissions Permission, group string, folderId uint) (*ShareResult, error) {
+	return c.SetGroupPermissionsForGroupFolderContext(context.Background(), permissions, group, folderId)
+}
+
+// SetGroupPermissionsForGroupFolderContext is like
+// SetGroupPermissionsForGroupFolder but aborts the request when ctx
+// is done.
+func (c *Client) SetGroupPermissionsForGroupFolderContext(ctx context.Context, permissions Permission, group string, folderId uint) (*ShareResult, error) {
+	return c.sendGroupFoldersRequest(ctx, "POST", fmt.Sprintf("folders/%d/groups/%s", folderId, url.PathEscape(group)), fmt.Sprintf("permissions=%d", permissions))
+}
+
+// CreateLegacyShare shares path, publicUpload being "true" to allow
+// uploads to a public link.
+//
+// Deprecated: CreateLegacyShare is the former CreateShare. Use
+// CreateShare with ShareOptions, which covers all the settings of a
+// share.
+func (c *Client) CreateLegacyShare(path string, shareType int, publicUpload string, permissions int) (*ShareResult, error) {
+	return c.CreateLegacyShareContext(context.Background(), path, shareType, publicUpload, permissions)
+}
+
+// CreateLegacyShareContext is like CreateLegacyShare but aborts the
+// requests when ctx is done.
+//
+// Deprecated: Use CreateShareContext with ShareOptions.
+func (c *Client) CreateLegacyShareContext(ctx context.Context, path string, shareType int, publicUpload string, permissions int) (*ShareResult, error) {
+	if c.DeduplicateShares {
+		result, err := c.existingShare(ctx, path, shareType, "", permissions)
+		if err != nil || result != nil {
+			return result, err
+		}
 	}
+	data := url.Values{}
+	data.Set("path", path)
+	data.Set("shareType", strconv.Itoa(shareType))
+	data.Set("publicUpload", publicUpload)
+	data.Set("permissions", strconv.Itoa(permissions))
+	return c.sendSharesRequest(ctx, "POST", "shares", data.Encode())
+}
 
-	body, err := ioutil.ReadAll(resp.Body)
+func (c *Client) GetShare(path string) (*ShareResult, error) {
+	return c.GetShareContext(context.Background(), path)
+}
+
+// GetShareContext is like GetShare but aborts the request when ctx
+// is done.
+func (c *Client) GetShareContext(ctx context.Context, path string) (*ShareResult, error) {
+	return c.sendSharesRequest(ctx, "GET", "shares?"+url.Values{"path": {path}}.Encode(), "")
+}
+
+// GetSharesForPath returns the shares on the given path. If
+// includeReshares is true, shares created by other users on the same
+// file are returned too. If includeSubfiles is true, path must be a
+// folder and the shares on its direct children are returned instead.
+func (c *Client) GetSharesForPath(path string, includeReshares, includeSubfiles bool) ([]Share, error) {
+	return c.GetSharesForPathContext(context.Background(), path, includeReshares, includeSubfiles)
+}
+
+// GetSharesForPathContext is like GetSharesForPath but aborts the
+// request when ctx is done.
+func (c *Client) GetSharesForPathContext(ctx context.Context, path string, includeReshares, includeSubfiles bool) ([]Share, error) {
+	query := url.Values{}
+	query.Set("path", path)
+	query.Set("reshares", strconv.FormatBool(includeReshares))
+	query.Set("subfiles", strconv.FormatBool(includeSubfiles))
+	result, err := c.sendSharesRequest(ctx, "GET", "shares?"+query.Encode(), "")
 	if err != nil {
 		return nil, err
 	}
+	return result.Elements, nil
+}
 
-	if len(body) > 0 {
-		if body[0] == '<' {
-			error := Error{}
-			err = xml.Unmarshal(body, &error)
-			if err != nil {
-				return body, err
-			}
-			if error.Exception != "" {
-				return nil, err
-			}
+func (c *Client) DeleteShare(id uint) (*ShareResult, error) {
+	return c.DeleteShareContext(context.Background(), id)
+}
+
+// DeleteShareContext is like DeleteShare but aborts the request when
+// ctx is done.
+func (c *Client) DeleteShareContext(ctx context.Context, id uint) (*ShareResult, error) {
+	return c.sendSharesRequest(ctx, "DELETE", fmt.Sprintf("shares/%d", id), "")
+}
+
+func (c *Client) CreateFileDropShare(path string) (*ShareResult, error) {
+	return c.CreateFileDropShareContext(context.Background(), path)
+}
+
+// CreateFileDropShareContext is like CreateFileDropShare but aborts
+// the requests when ctx is done.
+func (c *Client) CreateFileDropShareContext(ctx context.Context, path string) (*ShareResult, error) {
+	return c.createShare(ctx, path, ShareOptions{
+		ShareType:    ShareTypePublic,
+		Permissions:  PermissionCreate,
+		PublicUpload: true,
+	})
+}
+
+func (c *Client) CreateReadOnlyShare(path string) (*ShareResult, error) {
+	return c.CreateReadOnlyShareContext(context.Background(), path)
+}
+
+// CreateReadOnlyShareContext is like CreateReadOnlyShare but aborts
+// the requests when ctx is done.
+func (c *Client) CreateReadOnlyShareContext(ctx context.Context, path string) (*ShareResult, error) {
+	return c.createShare(ctx, path, ShareOptions{
+		ShareType:   ShareTypePublic,
+		Permissions: PermissionRead,
+	})
+}
+
+// httpClient returns the client used to send requests. It may be
+// shared, so it must not be modified.
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		if c.HTTPClient.CheckRedirect != nil {
+			return c.HTTPClient
+		}
+		client := *c.HTTPClient
+		client.CheckRedirect = c.checkRedirect
+		return &client
+	}
+	c.defaultClientOnce.Do(func() {
+		c.defaultClient = &http.Client{
+			Transport:     defaultTransport,
+			CheckRedirect: c.checkRedirect,
 		}
+	})
+	return c.defaultClient
+}
 
+// send sends req with the HTTP client and reports it to c.OnRequest.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.OnRequest == nil {
+		return c.httpClient().Do(req)
 	}
 
-	return body, nil
+	start := time.Now()
+	resp, err := c.httpClient().Do(req)
+	status := 0
+	if err == nil {
+		status = resp.StatusCode
+	}
+	c.OnRequest(req.Method, redactURL(req.URL), status, time.Since(start), err)
+	return resp, err
 }
 
-func (c *Client) sendAppsRequest(request string, path string, data string) (*ShareResult, error) {
-	// Create the https request
+// checkRedirect is the default redirect policy. It follows
+// redirects on the same host only, preserving the credentials, and
+// rejects cross-host ones so that credentials never leave the
+// server.
+func (c *Client) checkRedirect(req *http.Request, via []*http.Request) error {
+	if c.CheckRedirect != nil {
+		return c.CheckRedirect(req, via)
+	}
+	max := c.MaxRedirects
+	if max < 0 {
+		return http.ErrUseLastResponse
+	}
+	if max == 0 {
+		max = defaultMaxRedirects
+	}
+	if len(via) >= max {
+		return fmt.Errorf("stopped after %d redirects", max)
+	}
+	if req.URL.Host != via[0].URL.Host {
+		return fmt.Errorf("refusing to follow cross-host redirect to %s", req.URL.Host)
+	}
+	c.authenticate(req)
+	return nil
+}
 
-	appsPath := filepath.Join("apps", path)
+// newWebDavRequest returns an authenticated request for the given
+// path on the WebDAV endpoint.
+func (c *Client) newWebDavRequest(method string, p string, body io.Reader) (*http.Request, error) {
+	return c.newWebDavRequestContext(context.Background(), method, p, body)
+}
 
-	folderUrl, err := url.Parse(appsPath)
-	if err != nil {
-		return nil, err
+// newWebDavRequestContext is like newWebDavRequest but the request
+// is bound to ctx.
+func (c *Client) newWebDavRequestContext(ctx context.Context, method string, p string, body io.Reader) (*http.Request, error) {
+	return c.newRequestContext(ctx, method, path.Join(c.webDAVRoot(), p), body)
+}
+
+// webDAVRoot returns the configured WebDAV root, or the default
+// one.
+func (c *Client) webDAVRoot() string {
+	if c.WebDAVRoot == "" {
+		return defaultWebDAVRoot
 	}
+	return c.WebDAVRoot
+}
+
+// defaultWebDAVRoot is the legacy WebDAV endpoint.
+const defaultWebDAVRoot = "remote.php/webdav"
+
+// FilesRoot returns the path of the files of the given user on the
+// DAV endpoint of modern servers, to be used as Client.WebDAVRoot.
+func FilesRoot(username string) string {
+	return path.Join("remote.php/dav/files", username)
+}
+
+// WebDAVURL returns the absolute, escaped URL of the given path on
+// the WebDAV endpoint. It can be handed to other WebDAV clients or
+// downloaders, which must provide the credentials themselves.
+func (c *Client) WebDAVURL(p string) string {
+	return c.resolve(path.Join(c.webDAVRoot(), p))
+}
+
+// newRequest returns an authenticated request for the given path,
+// relative to the server URL.
+func (c *Client) newRequest(method string, path string, body io.Reader) (*http.Request, error) {
+	return c.newRequestContext(context.Background(), method, path, body)
+}
 
-	client := &http.Client{}
-	req, err := http.NewRequest(request, c.Url.ResolveReference(folderUrl).String(), strings.NewReader(data))
+// newRequestContext is like newRequest but the request is bound to
+// ctx.
+func (c *Client) newRequestContext(ctx context.Context, method string, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.resolve(path), body)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("OCS-APIRequest", "true")
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	c.authenticate(req)
 
-	req.SetBasicAuth(c.Username, c.Password)
+	return req, nil
+}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
+// contextErr returns ctx.Err() if ctx is done, err otherwise, so
+// that a cancelled request reports the cancellation rather than the
+// transport error it caused.
+func contextErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
 	}
+	return err
+}
 
-	body, err := ioutil.ReadAll(resp.Body)
+// resolve returns the absolute URL of the given path, relative to
+// the server URL. The path is escaped, so characters like spaces, '?'
+// and '#' are part of the resulting path.
+func (c *Client) resolve(path string) string {
+	return c.Url.ResolveReference(&url.URL{Path: path}).String()
+}
+
+// mkdirExist creates the given folder on the WebDAV endpoint. Unlike
+// Mkdir, it doesn't fail if the folder already exists.
+func (c *Client) mkdirExist(dir string) error {
+	req, err := c.newWebDavRequest("MKCOL", dir, nil)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	result := ShareResult{}
-	err = xml.Unmarshal(body, &result)
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	if result.StatusCode != 100 {
-		return nil, fmt.Errorf("Share API returned an unsuccessful status code %d", result.StatusCode)
+	resp.Body.Close()
+	// 405 Method Not Allowed means that the folder already
+	// exists.
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed {
+		return newStatusError(resp)
 	}
-
-	return &result, nil
+	return nil
 }
 
-func (c *Client) sendOCSRequest(request string, path string, data string) (*ShareResult, error) {
+func (c *Client) sendWebDavRequest(ctx context.Context, request string, path string, data []byte) ([]byte, error) {
 	// Create the https request
 
-	appsPath := filepath.Join("ocs/v2.php/apps/files_sharing/api/v1", path)
-
-	folderUrl, err := url.Parse(appsPath)
+	req, err := c.newWebDavRequestContext(ctx, request, path, bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
 
-	client := &http.Client{}
-	req, err := http.NewRequest(request, c.Url.ResolveReference(folderUrl).String(), strings.NewReader(data))
+	resp, err := c.do(req)
 	if err != nil {
-		return nil, err
+		return nil, contextErr(ctx, err)
 	}
+	defer resp.Body.Close()
 
-	req.Header.Add("OCS-APIRequest", "true")
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-
-	req.SetBasicAuth(c.Username, c.Password)
+	if resp.StatusCode == http.StatusLocked {
+		return nil, ErrLocked
+	}
 
-	resp, err := client.Do(req)
+	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
+		return nil, contextErr(ctx, err)
+	}
+
+	if resp.StatusCode/100 != 2 {
+		// The body of an error may describe it, while the body of
+		// a success is the content of a file, whatever it holds.
+		if len(body) > 0 && body[0] == '<' {
+			error := Error{}
+			err = xml.Unmarshal(body, &error)
+			if err == nil && error.Exception != "" {
+				return nil, &error
+			}
+		}
+		return nil, newStatusError(resp)
+	}
+
+	return body, nil
+}
+
+// sendGroupFoldersRequest sends a request to the group folders app,
+// which is available on Nextcloud only.
+func (c *Client) sendGroupFoldersRequest(ctx context.Context, request string, path string, data string) (*ShareResult, error) {
+	if err := c.requireFlavor(FlavorNextcloud); err != nil {
 		return nil, err
 	}
+	return c.sendAppsRequest(ctx, request, "groupfolders/"+path, data)
+}
 
-	body, err := ioutil.ReadAll(resp.Body)
+// sendAppsRequest sends a request to the given endpoint of the apps
+// API, e.g. "groupfolders/folders".
+func (c *Client) sendAppsRequest(ctx context.Context, request string, endpoint string, data string) (*ShareResult, error) {
+	body, meta, err := c.sendOCSRequest(ctx, request, path.Join("apps", endpoint), data)
 	if err != nil {
 		return nil, err
 	}
+	if meta.StatusCode != 100 {
+		return nil, meta.err()
+	}
+	return c.newShareResult(meta, body)
+}
 
-	result := ShareResult{}
-
-	err = xml.Unmarshal(body, &result)
+// sendSharesRequest sends a request to the given endpoint of the
+// share API, e.g. "shares".
+func (c *Client) sendSharesRequest(ctx context.Context, request string, endpoint string, data string) (*ShareResult, error) {
+	body, meta, err := c.sendOCSRequest(ctx, request, path.Join("ocs/v2.php/apps/files_sharing/api/v1", endpoint), data)
 	if err != nil {
 		return nil, err
 	}
-	if result.StatusCode != 200 {
-		return nil, fmt.Errorf("Share API returned an unsuccessful status code %d", result.StatusCode)
+	if meta.StatusCode != 200 {
+		return nil, meta.err()
+	}
+	return c.newShareResult(meta, body)
+}
+
+// newShareResult returns the result of a request to the share or
+// group folders API, given the meta block and the data element of
+// the response.
+func (c *Client) newShareResult(meta *OCSMeta, data []byte) (*ShareResult, error) {
+	content := struct {
+		Id       ocsUint `xml:"id" json:"id"`
+		Url      string  `xml:"url" json:"url"`
+		Elements []Share `xml:"element" json:"element"`
+	}{}
+	if err := c.unmarshalOCS(data, &content); err != nil {
+		return nil, err
+	}
+
+	result := &ShareResult{
+		XMLName:    xml.Name{Local: "ocs"},
+		Status:     meta.Status,
+		StatusCode: meta.StatusCode,
+		Message:    meta.Message,
+		Id:         uint(content.Id),
+		Url:        content.Url,
+		Elements:   content.Elements,
 	}
 
-	return &result, nil
+	// The data element is either a single share, e.g. when one is
+	// created, or a list of shares. Both are made available in the
+	// same way.
+	switch {
+	case len(result.Elements) == 0 && result.Id != 0:
+		var share Share
+		if err := c.unmarshalOCS(data, &share); err != nil {
+			return nil, err
+		}
+		result.Elements = []Share{share}
+	case len(result.Elements) == 1 && result.Id == 0:
+		result.Id = result.Elements[0].Id
+		result.Url = result.Elements[0].Url
+	}
+	return result, nil
 }
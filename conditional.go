@@ -0,0 +1,83 @@
+package cloud
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// quoteETag returns etag as a quoted entity tag, as expected by the
+// conditional headers. ETags are accepted with or without quotes.
+func quoteETag(etag string) string {
+	if strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// DownloadIfChanged downloads the file at path unless its ETag is
+// still etag, as returned by a previous download or listing. It
+// returns the content and the current ETag of the file, and whether
+// it changed. If it didn't, the content is nil. An empty etag always
+// downloads the file.
+func (c *Client) DownloadIfChanged(path, etag string) ([]byte, string, bool, error) {
+	start := time.Now()
+	data, current, changed, err := c.downloadIfChanged(path, etag)
+	c.emit(OpDownload, path, int64(len(data)), start, err)
+	return data, current, changed, err
+}
+
+// downloadIfChanged downloads the file at path unless its ETag is
+// still etag, see DownloadIfChanged.
+func (c *Client) downloadIfChanged(path, etag string) ([]byte, string, bool, error) {
+	req, err := c.newWebDavRequest("GET", path, nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", quoteETag(etag))
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return nil, etag, false, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, "", false, newStatusError(resp)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", false, err
+	}
+	return data, resp.Header.Get("ETag"), true, nil
+}
+
+// UploadIfMatch uploads src to dest only if the ETag of dest is
+// still etag, as returned by a previous download or listing.
+// Otherwise the server refuses the upload and ErrPreconditionFailed
+// is returned, so that concurrent changes are never overwritten.
+func (c *Client) UploadIfMatch(src []byte, dest, etag string) error {
+	req, err := c.newWebDavRequest("PUT", dest, bytes.NewReader(src))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("If-Match", quoteETag(etag))
+
+	start := time.Now()
+	err = c.sendUpload(req, func(resp *http.Response) error {
+		if resp.StatusCode == http.StatusPreconditionFailed {
+			return ErrPreconditionFailed
+		}
+		return nil
+	})
+	c.emit(OpUpload, dest, int64(len(src)), start, err)
+	return err
+}
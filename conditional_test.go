@@ -0,0 +1,43 @@
+package cloud
+
+func (t *testSuite) TestDownloadIfChanged() {
+	s := newDavServer()
+	defer s.Close()
+	s.files["/test.txt"] = []byte("Hello")
+	c := s.client()
+
+	data, etag1, changed, err := c.DownloadIfChanged("test.txt", "")
+	t.Nil(err)
+	t.True(changed)
+	t.Equal("Hello", string(data))
+	t.Equal(etag([]byte("Hello")), etag1)
+
+	data, etag2, changed, err := c.DownloadIfChanged("test.txt", etag1)
+	t.Nil(err)
+	t.False(changed)
+	t.Nil(data)
+	t.Equal(etag1, etag2)
+
+	s.files["/test.txt"] = []byte("Hello World!")
+	data, etag2, changed, err = c.DownloadIfChanged("test.txt", etag1)
+	t.Nil(err)
+	t.True(changed)
+	t.Equal("Hello World!", string(data))
+	t.Equal(etag([]byte("Hello World!")), etag2)
+}
+
+func (t *testSuite) TestUploadIfMatch() {
+	s := newDavServer()
+	defer s.Close()
+	s.files["/test.txt"] = []byte("Hello")
+	c := s.client()
+
+	info, err := c.Stat("test.txt")
+	t.Nil(err)
+
+	t.Nil(c.UploadIfMatch([]byte("mine"), "test.txt", info.ETag))
+	t.Equal("mine", string(s.files["/test.txt"]))
+
+	t.Equal(ErrPreconditionFailed, c.UploadIfMatch([]byte("stale"), "test.txt", info.ETag))
+	t.Equal("mine", string(s.files["/test.txt"]))
+}
@@ -0,0 +1,499 @@
+package cloud
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	propfindHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<d:propfind xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns" xmlns:nc="http://nextcloud.org/ns" xmlns:cal="urn:ietf:params:xml:ns:caldav" xmlns:ical="http://apple.com/ns/ical/"><d:prop>`
+	propfindFooter = `</d:prop></d:propfind>`
+)
+
+// multistatus is the body of a 207 Multi-Status response.
+type multistatus struct {
+	Responses []davResponse `xml:"DAV: response"`
+}
+
+type davResponse struct {
+	Href      string        `xml:"DAV: href"`
+	Propstats []davPropstat `xml:"DAV: propstat"`
+}
+
+type davPropstat struct {
+	Prop   davProp `xml:"DAV: prop"`
+	Status string  `xml:"DAV: status"`
+}
+
+// davProp collects the properties requested by the client. Only the
+// ones found by the server are filled.
+type davProp struct {
+	FileId       string `xml:"http://owncloud.org/ns fileid"`
+	Checksum     string `xml:"http://owncloud.org/ns checksums>checksum"`
+	ResourceType struct {
+		Collection *struct{} `xml:"DAV: collection"`
+		Calendar   *struct{} `xml:"urn:ietf:params:xml:ns:caldav calendar"`
+	} `xml:"DAV: resourcetype"`
+	DisplayName    string `xml:"DAV: displayname"`
+	CalendarColor  string `xml:"http://apple.com/ns/ical/ calendar-color"`
+	ContentLength  int64  `xml:"DAV: getcontentlength"`
+	LastModified   string `xml:"DAV: getlastmodified"`
+	ContentType    string `xml:"DAV: getcontenttype"`
+	ETag           string `xml:"DAV: getetag"`
+	QuotaUsed      int64  `xml:"DAV: quota-used-bytes"`
+	QuotaAvailable string `xml:"DAV: quota-available-bytes"`
+	Size           int64  `xml:"http://owncloud.org/ns size"`
+	CreationTime   int64  `xml:"http://nextcloud.org/ns creation_time"`
+	UploadTime     int64  `xml:"http://nextcloud.org/ns upload_time"`
+
+	TagId             string `xml:"http://owncloud.org/ns id"`
+	TagName           string `xml:"http://owncloud.org/ns display-name"`
+	TagUserVisible    bool   `xml:"http://owncloud.org/ns user-visible"`
+	TagUserAssignable bool   `xml:"http://owncloud.org/ns user-assignable"`
+
+	TrashbinFilename         string `xml:"http://nextcloud.org/ns trashbin-filename"`
+	TrashbinOriginalLocation string `xml:"http://nextcloud.org/ns trashbin-original-location"`
+	TrashbinDeletionTime     int64  `xml:"http://nextcloud.org/ns trashbin-deletion-time"`
+}
+
+// fileInfoProps are the properties needed to fill a FileInfo.
+const fileInfoProps = "<d:resourcetype/><d:getcontentlength/><d:getlastmodified/><d:getcontenttype/><d:getetag/><oc:size/><nc:creation_time/><nc:upload_time/><oc:checksums/>"
+
+// FileInfo describes a file or folder on the cloud.
+type FileInfo struct {
+	// Name is the base name of the file.
+	Name string
+
+	// Path is the path of the file, relative to the user's root.
+	Path string
+
+	// Href is the escaped path of the file on the server, as
+	// reported by the server.
+	Href string
+
+	// Size is the length in bytes of a file, or the total size of
+	// the content of a folder.
+	Size int64
+
+	// ModTime is the last modification time.
+	ModTime time.Time
+
+	// CreationTime and UploadTime are the time the file was
+	// created and uploaded, when known by the server. They are
+	// the zero time otherwise.
+	CreationTime time.Time
+	UploadTime   time.Time
+
+	ContentType string
+
+	// ETag identifies the version of the file: it changes
+	// whenever the content does.
+	ETag string
+
+	// Checksums are the checksums of the content stored by the
+	// server, by algorithm, e.g. "SHA1". The server stores them
+	// only when they are supplied on upload, see
+	// UploadWithChecksum.
+	Checksums map[string]string
+
+	IsDir bool
+}
+
+// mode returns the file mode bits of f. The server doesn't report
+// permission bits, so the ones of a read-only file system are used.
+func (f *FileInfo) mode() fs.FileMode {
+	if f.IsDir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+// fsInfo returns f as an fs.FileInfo.
+func (f FileInfo) fsInfo() fs.FileInfo {
+	return fsFileInfo{&f}
+}
+
+// fsFileInfo implements fs.FileInfo. Sys returns the underlying
+// *FileInfo.
+type fsFileInfo struct {
+	info *FileInfo
+}
+
+func (i fsFileInfo) Name() string       { return i.info.Name }
+func (i fsFileInfo) Size() int64        { return i.info.Size }
+func (i fsFileInfo) Mode() fs.FileMode  { return i.info.mode() }
+func (i fsFileInfo) ModTime() time.Time { return i.info.ModTime }
+func (i fsFileInfo) IsDir() bool        { return i.info.IsDir }
+func (i fsFileInfo) Sys() interface{}   { return i.info }
+
+// prop returns the properties found by the server.
+func (r *davResponse) prop() davProp {
+	for _, propstat := range r.Propstats {
+		if strings.Contains(propstat.Status, " 200 ") {
+			return propstat.Prop
+		}
+	}
+	return davProp{}
+}
+
+// isCollection reports whether the resource is a folder.
+func (p *davProp) isCollection() bool {
+	return p.ResourceType.Collection != nil
+}
+
+// propfind requests the given properties, e.g. "<oc:fileid/>", of
+// path and, depending on depth, of its children.
+func (c *Client) propfind(path string, depth string, props ...string) (*multistatus, error) {
+	req, err := c.newWebDavRequest("PROPFIND", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.sendPropfindRequest(req, depth, props)
+}
+
+// davPropfind is like propfind, for a path relative to the server
+// URL, e.g. "remote.php/dav/calendars/admin".
+func (c *Client) davPropfind(davPath string, depth string, props ...string) (*multistatus, error) {
+	req, err := c.newRequest("PROPFIND", davPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.sendPropfindRequest(req, depth, props)
+}
+
+func (c *Client) sendPropfindRequest(req *http.Request, depth string, props []string) (*multistatus, error) {
+	setPropfindBody(req, depth, props)
+	return c.sendMultistatusRequest(req)
+}
+
+// setPropfindBody sets the body of the PROPFIND request req, asking
+// for the given properties.
+func setPropfindBody(req *http.Request, depth string, props []string) {
+	body := propfindHeader + strings.Join(props, "") + propfindFooter
+	req.Body = ioutil.NopCloser(strings.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Depth", depth)
+}
+
+// report sends a filter-files REPORT to the given path of the DAV
+// endpoint, e.g. "remote.php/dav/files/admin", requesting props of
+// the files matching the filter rules.
+func (c *Client) report(davPath string, rules string, props ...string) (*multistatus, error) {
+	body := `<?xml version="1.0" encoding="UTF-8"?>
+<oc:filter-files xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns" xmlns:nc="http://nextcloud.org/ns"><d:prop>` +
+		strings.Join(props, "") + `</d:prop><oc:filter-rules>` + rules + `</oc:filter-rules></oc:filter-files>`
+	req, err := c.newRequest("REPORT", davPath, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	return c.sendMultistatusRequest(req)
+}
+
+// sendMultistatusRequest sends req, whose body is an XML document,
+// and parses the 207 Multi-Status response.
+func (c *Client) sendMultistatusRequest(req *http.Request) (*multistatus, error) {
+	result := multistatus{}
+	err := c.streamMultistatusRequest(req, func(r *davResponse) error {
+		result.Responses = append(result.Responses, *r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// streamMultistatusRequest sends req, whose body is an XML document,
+// and calls fn with each response of the 207 Multi-Status response
+// as soon as it is parsed, so that the listing of a large folder is
+// never buffered. An error returned by fn stops the parsing.
+func (c *Client) streamMultistatusRequest(req *http.Request, fn func(r *davResponse) error) error {
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMultiStatus {
+		return newStatusError(resp)
+	}
+
+	decoder := xml.NewDecoder(resp.Body)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name != (xml.Name{Space: "DAV:", Local: "response"}) {
+			continue
+		}
+		var r davResponse
+		if err := decoder.DecodeElement(&r, &start); err != nil {
+			return err
+		}
+		if err := fn(&r); err != nil {
+			return err
+		}
+	}
+}
+
+// fileId returns the server-side id of the file at path.
+func (c *Client) fileId(path string) (string, error) {
+	result, err := c.propfind(path, "0", "<oc:fileid/>")
+	if err != nil {
+		return "", err
+	}
+	if len(result.Responses) == 0 {
+		return "", fmt.Errorf("PROPFIND %s returned no response", path)
+	}
+	id := result.Responses[0].prop().FileId
+	if id == "" {
+		return "", fmt.Errorf("file id of %s not found", path)
+	}
+	return id, nil
+}
+
+// filePath returns the path, relative to the user's root, of the
+// file with the given server-side id. It uses a WebDAV SEARCH, which
+// is supported by Nextcloud 15 and later.
+func (c *Client) filePath(fileId string) (string, error) {
+	var id bytes.Buffer
+	xml.EscapeText(&id, []byte(fileId))
+	var scope bytes.Buffer
+	xml.EscapeText(&scope, []byte(path.Join("/files", c.Username)))
+
+	body := `<?xml version="1.0" encoding="UTF-8"?>
+<d:searchrequest xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns"><d:basicsearch>
+<d:select><d:prop><oc:fileid/></d:prop></d:select>
+<d:from><d:scope><d:href>` + scope.String() + `</d:href><d:depth>infinity</d:depth></d:scope></d:from>
+<d:where><d:eq><d:prop><oc:fileid/></d:prop><d:literal>` + id.String() + `</d:literal></d:eq></d:where>
+</d:basicsearch></d:searchrequest>`
+	req, err := c.newRequest("SEARCH", "remote.php/dav/", strings.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	result, err := c.sendMultistatusRequest(req)
+	if err != nil {
+		return "", err
+	}
+	if len(result.Responses) == 0 {
+		return "", fmt.Errorf("file with id %s not found", fileId)
+	}
+
+	return c.hrefPath(result.Responses[0].Href, c.filesRoot())
+}
+
+// hrefPath returns the path, relative to the given root of the DAV
+// endpoint, of an href found in a multistatus response.
+func (c *Client) hrefPath(href string, root string) (string, error) {
+	p, err := url.PathUnescape(href)
+	if err != nil {
+		return "", err
+	}
+	prefix := c.Url.ResolveReference(&url.URL{Path: root}).Path
+	return path.Clean("/" + strings.TrimPrefix(p, prefix)), nil
+}
+
+// filesRoot returns the path of the user's files on the DAV
+// endpoint.
+func (c *Client) filesRoot() string {
+	return FilesRoot(c.Username)
+}
+
+// fileInfo returns the description of the resource found in a
+// multistatus response, whose href is relative to root.
+func (c *Client) fileInfo(r *davResponse, root string) (FileInfo, error) {
+	p, err := c.hrefPath(r.Href, root)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	prop := r.prop()
+	info := FileInfo{
+		Name:        path.Base(p),
+		Path:        p,
+		Href:        r.Href,
+		Size:        prop.ContentLength,
+		ContentType: prop.ContentType,
+		ETag:        prop.ETag,
+		IsDir:       prop.isCollection(),
+	}
+	if info.IsDir {
+		info.Size = prop.Size
+	}
+	if prop.Checksum != "" {
+		info.Checksums = parseChecksums(prop.Checksum)
+	}
+	if prop.CreationTime > 0 {
+		info.CreationTime = time.Unix(prop.CreationTime, 0)
+	}
+	if prop.UploadTime > 0 {
+		info.UploadTime = time.Unix(prop.UploadTime, 0)
+	}
+	if prop.LastModified != "" {
+		info.ModTime, err = http.ParseTime(prop.LastModified)
+		if err != nil {
+			return FileInfo{}, err
+		}
+	}
+	return info, nil
+}
+
+// ErrNotFound is returned when the requested file doesn't exist.
+var ErrNotFound = errors.New("file not found")
+
+// Stat returns the description of the file or folder at p. If it
+// doesn't exist, ErrNotFound is returned.
+func (c *Client) Stat(p string) (*FileInfo, error) {
+	result, err := c.propfind(p, "0", fileInfoProps)
+	if statusErr, ok := err.(*StatusError); ok && statusErr.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Responses) != 1 {
+		return nil, fmt.Errorf("PROPFIND %s: got %d responses, want 1", p, len(result.Responses))
+	}
+
+	info, err := c.fileInfo(&result.Responses[0], c.webDAVRoot())
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// List returns the files and folders contained in the folder p,
+// p itself excluded.
+func (c *Client) List(p string) ([]FileInfo, error) {
+	dir := path.Clean("/" + p)
+	result, err := c.propfind(dir, "1", fileInfoProps)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []FileInfo
+	for i := range result.Responses {
+		info, err := c.fileInfo(&result.Responses[i], c.webDAVRoot())
+		if err != nil {
+			return nil, err
+		}
+		if info.Path == dir {
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// DirEntry is an entry of a folder returned by ReadDir. It
+// implements fs.DirEntry.
+type DirEntry struct {
+	info FileInfo
+}
+
+func (e DirEntry) Name() string {
+	return e.info.Name
+}
+
+func (e DirEntry) IsDir() bool {
+	return e.info.IsDir
+}
+
+// Type returns the type bits of the entry: fs.ModeDir for a folder,
+// zero for a file.
+func (e DirEntry) Type() fs.FileMode {
+	return e.info.mode().Type()
+}
+
+// Info returns the description of the entry, which was read along
+// with the folder: it sends no request.
+func (e DirEntry) Info() (fs.FileInfo, error) {
+	return e.info.fsInfo(), nil
+}
+
+// ReadDir returns the entries of the folder p sorted by name, like
+// os.ReadDir. The listing is parsed as it is received, so that large
+// folders are never buffered.
+func (c *Client) ReadDir(p string) ([]DirEntry, error) {
+	dir := path.Clean("/" + p)
+	req, err := c.newWebDavRequest("PROPFIND", dir, nil)
+	if err != nil {
+		return nil, err
+	}
+	setPropfindBody(req, "1", []string{fileInfoProps})
+
+	var entries []DirEntry
+	err = c.streamMultistatusRequest(req, func(r *davResponse) error {
+		info, err := c.fileInfo(r, c.webDAVRoot())
+		if err != nil {
+			return err
+		}
+		if info.Path != dir {
+			entries = append(entries, DirEntry{info})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// walk lists recursively the tree rooted at p. Folders are returned
+// parents first, p itself excluded.
+func (c *Client) walk(p string) (files []string, dirs []string, err error) {
+	pending := []string{path.Clean("/" + p)}
+	for len(pending) > 0 {
+		dir := pending[0]
+		pending = pending[1:]
+
+		result, err := c.propfind(dir, "1", "<d:resourcetype/>")
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, response := range result.Responses {
+			child, err := c.hrefPath(response.Href, c.webDAVRoot())
+			if err != nil {
+				return nil, nil, err
+			}
+			if child == dir {
+				continue
+			}
+			prop := response.prop()
+			if prop.isCollection() {
+				dirs = append(dirs, child)
+				pending = append(pending, child)
+			} else {
+				files = append(files, child)
+			}
+		}
+	}
+	return files, dirs, nil
+}
@@ -0,0 +1,334 @@
+package cloud
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// davServer is an in-memory WebDAV server implementing the subset of
+// the protocol used by the package.
+type davServer struct {
+	*httptest.Server
+
+	// root is the path of the WebDAV endpoint.
+	root string
+
+	mu       sync.Mutex
+	files    map[string][]byte
+	dirs     map[string]bool
+	requests []string
+}
+
+func newDavServer() *davServer {
+	s := newUnstartedDavServer()
+	s.Start()
+	return s
+}
+
+// newUnstartedDavServer is like newDavServer but doesn't start the
+// server, so that its configuration can be changed first.
+func newUnstartedDavServer() *davServer {
+	s := &davServer{
+		root:  "/remote.php/webdav",
+		files: make(map[string][]byte),
+		dirs:  map[string]bool{"/": true},
+	}
+	s.Server = httptest.NewUnstartedServer(s)
+	return s
+}
+
+func (s *davServer) client() *Client {
+	c, err := Dial(s.URL+"/", "admin", "password")
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func (s *davServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	root := s.root
+	if !strings.HasPrefix(r.URL.Path, root) {
+		http.NotFound(w, r)
+		return
+	}
+	p := path.Clean("/" + strings.TrimPrefix(r.URL.Path, root))
+	s.requests = append(s.requests, r.Method+" "+p)
+
+	switch r.Method {
+	case "GET":
+		data, ok := s.files[p]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("ETag", etag(data))
+		if r.Header.Get("If-None-Match") == etag(data) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		var first, last int
+		if n, _ := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &first, &last); n == 2 && first < len(data) {
+			if last >= len(data) {
+				last = len(data) - 1
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", first, last, len(data)))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(data[first : last+1])
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		w.Write(data)
+	case "PUT":
+		if !s.dirs[path.Dir(p)] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if match := r.Header.Get("If-Match"); match != "" && (s.files[p] == nil || match != etag(s.files[p])) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		s.files[p] = data
+		w.WriteHeader(http.StatusCreated)
+	case "MKCOL":
+		if s.dirs[p] || s.files[p] != nil {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if !s.dirs[path.Dir(p)] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.dirs[p] = true
+		w.WriteHeader(http.StatusCreated)
+	case "DELETE":
+		if !s.exists(p) {
+			http.NotFound(w, r)
+			return
+		}
+		s.remove(p)
+		w.WriteHeader(http.StatusNoContent)
+	case "MOVE", "COPY":
+		if !s.exists(p) {
+			http.NotFound(w, r)
+			return
+		}
+		u, err := url.Parse(r.Header.Get("Destination"))
+		if err != nil || !strings.HasPrefix(u.Path, root) {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		dest := path.Clean("/" + strings.TrimPrefix(u.Path, root))
+		if !s.dirs[path.Dir(dest)] {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		status := http.StatusCreated
+		if s.exists(dest) {
+			if r.Header.Get("Overwrite") == "F" {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			s.remove(dest)
+			status = http.StatusNoContent
+		}
+		for name, data := range s.files {
+			if name == p || strings.HasPrefix(name, p+"/") {
+				s.files[dest+strings.TrimPrefix(name, p)] = data
+			}
+		}
+		for name := range s.dirs {
+			if name == p || strings.HasPrefix(name, p+"/") {
+				s.dirs[dest+strings.TrimPrefix(name, p)] = true
+			}
+		}
+		if r.Method == "MOVE" {
+			s.remove(p)
+		}
+		w.WriteHeader(status)
+	case "PROPFIND":
+		if !s.exists(p) {
+			http.NotFound(w, r)
+			return
+		}
+		names := []string{p}
+		if r.Header.Get("Depth") != "0" {
+			names = append(names, s.children(p)...)
+		}
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprint(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns">`)
+		for _, name := range names {
+			href := (&url.URL{Path: path.Join(root, name)}).EscapedPath()
+			if s.dirs[name] {
+				fmt.Fprintf(w, `<d:response><d:href>%s/</d:href><d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`, href)
+			} else {
+				fmt.Fprintf(w, `<d:response><d:href>%s</d:href><d:propstat><d:prop><d:resourcetype/><d:getcontentlength>%d</d:getcontentlength><d:getetag>%s</d:getetag></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`, href, len(s.files[name]), etag(s.files[name]))
+			}
+		}
+		fmt.Fprint(w, `</d:multistatus>`)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// remove deletes p and its content.
+func (s *davServer) remove(p string) {
+	for name := range s.files {
+		if name == p || strings.HasPrefix(name, p+"/") {
+			delete(s.files, name)
+		}
+	}
+	for name := range s.dirs {
+		if name == p || strings.HasPrefix(name, p+"/") {
+			delete(s.dirs, name)
+		}
+	}
+}
+
+// etag returns the ETag of a file with the given content.
+func etag(data []byte) string {
+	return fmt.Sprintf(`"%x"`, md5.Sum(data))
+}
+
+func (s *davServer) exists(p string) bool {
+	_, ok := s.files[p]
+	return ok || s.dirs[p]
+}
+
+// children returns the sorted direct children of the folder p.
+func (s *davServer) children(p string) []string {
+	var names []string
+	for name := range s.files {
+		if path.Dir(name) == p {
+			names = append(names, name)
+		}
+	}
+	for name := range s.dirs {
+		if name != "/" && path.Dir(name) == p {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (t *testSuite) TestList() {
+	s := newDavServer()
+	defer s.Close()
+	s.dirs["/Test"] = true
+	s.dirs["/Test/Sub Folder"] = true
+	s.files["/Test/a file.txt"] = []byte("Hello")
+	s.files["/Test/Sub Folder/nested.txt"] = []byte("nested")
+
+	infos, err := s.client().List("Test")
+	t.Nil(err)
+	t.Equal(2, len(infos))
+	if len(infos) == 2 {
+		t.Equal("Sub Folder", infos[0].Name)
+		t.True(infos[0].IsDir)
+		t.Equal("a file.txt", infos[1].Name)
+		t.Equal("/Test/a file.txt", infos[1].Path)
+		t.Equal("/remote.php/webdav/Test/a%20file.txt", infos[1].Href)
+		t.Equal(int64(5), infos[1].Size)
+		t.False(infos[1].IsDir)
+	}
+
+	_, err = s.client().List("Missing")
+	t.NotNil(err)
+}
+
+func (t *testSuite) TestStat() {
+	s := newDavServer()
+	defer s.Close()
+	s.dirs["/Test"] = true
+	s.files["/Test/a file.txt"] = []byte("Hello")
+	c := s.client()
+
+	info, err := c.Stat("Test/a file.txt")
+	t.Nil(err)
+	t.Equal(&FileInfo{
+		Name: "a file.txt",
+		Path: "/Test/a file.txt",
+		Href: "/remote.php/webdav/Test/a%20file.txt",
+		Size: 5,
+		ETag: etag([]byte("Hello")),
+	}, info)
+
+	info, err = c.Stat("Test")
+	t.Nil(err)
+	t.True(info.IsDir)
+
+	_, err = c.Stat("Missing")
+	t.Equal(ErrNotFound, err)
+}
+
+func (t *testSuite) TestWebDAVRoot() {
+	s := newDavServer()
+	defer s.Close()
+	s.root = "/remote.php/dav/files/admin"
+	c := s.client()
+	c.WebDAVRoot = FilesRoot(c.Username)
+
+	t.Equal(s.URL+"/remote.php/dav/files/admin/Test/a%20file.txt", c.WebDAVURL("Test/a file.txt"))
+	t.Nil(c.Mkdir("Test"))
+	t.Nil(c.Upload([]byte("Hello"), "Test/a file.txt"))
+	t.Equal("Hello", string(s.files["/Test/a file.txt"]))
+
+	infos, err := c.List("Test")
+	t.Nil(err)
+	t.Equal(1, len(infos))
+	if len(infos) == 1 {
+		t.Equal("/Test/a file.txt", infos[0].Path)
+	}
+	t.Nil(c.Move("Test/a file.txt", "b.txt"))
+	t.Nil(c.Delete("b.txt"))
+	t.Equal([]string{
+		"MKCOL /Test",
+		"PUT /Test/a file.txt",
+		"PROPFIND /Test",
+		"MOVE /Test/a file.txt",
+		"DELETE /b.txt",
+	}, s.requests)
+}
+
+func (t *testSuite) TestReadDir() {
+	s := newDavServer()
+	defer s.Close()
+	c := s.client()
+	_, err := c.UploadTree(testDir, "testdata")
+	t.Nil(err)
+
+	want, err := os.ReadDir(testDir)
+	t.Nil(err)
+	entries, err := c.ReadDir("testdata")
+	t.Nil(err)
+	t.Equal(len(want), len(entries))
+	for i := 0; i < len(want) && i < len(entries); i++ {
+		t.Equal(want[i].Name(), entries[i].Name())
+		t.Equal(want[i].IsDir(), entries[i].IsDir())
+		t.Equal(want[i].Type(), entries[i].Type())
+
+		wantInfo, err := want[i].Info()
+		t.Nil(err)
+		info, err := entries[i].Info()
+		t.Nil(err)
+		if !info.IsDir() {
+			t.Equal(wantInfo.Size(), info.Size())
+		}
+	}
+
+	_, err = c.ReadDir("missing")
+	t.NotNil(err)
+}
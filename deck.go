@@ -0,0 +1,50 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ErrDeckNotInstalled is returned by the Deck methods when the Deck
+// app is not enabled on the server.
+var ErrDeckNotInstalled = errors.New("the Deck app is not installed")
+
+// AttachFileToCard attaches the file at path to the given card of a
+// Deck board. The file is shared with the card, as the Deck web
+// interface does, so it is not copied.
+func (c *Client) AttachFileToCard(boardId, cardId int, path string) error {
+	ok, err := c.hasCapability("deck")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrDeckNotInstalled
+	}
+
+	req, err := c.newRequest("GET", fmt.Sprintf("index.php/apps/deck/api/v1.0/boards/%d", boardId), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("OCS-APIRequest", "true")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Deck board %d is not accessible: %s", boardId, resp.Status)
+	}
+
+	data := url.Values{}
+	data.Set("path", path)
+	data.Set("shareType", strconv.Itoa(ShareTypeDeck))
+	data.Set("shareWith", strconv.Itoa(cardId))
+	_, err = c.sendSharesRequest(context.Background(), "POST", "shares", data.Encode())
+	return err
+}
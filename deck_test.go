@@ -0,0 +1,46 @@
+package cloud
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+)
+
+func (t *testSuite) TestAttachFileToCard() {
+	var deck bool
+	var shared string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ocs/v2.php/cloud/capabilities":
+			capabilities := "<core><pollinterval>60</pollinterval></core>"
+			if deck {
+				capabilities += "<deck><version>1.9.0</version></deck>"
+			}
+			fmt.Fprintf(w, `<?xml version="1.0"?><ocs><meta><statuscode>200</statuscode></meta><data><capabilities>%s</capabilities></data></ocs>`, capabilities)
+		case "/index.php/apps/deck/api/v1.0/boards/1":
+			fmt.Fprint(w, `{"id":1}`)
+		case "/ocs/v2.php/apps/files_sharing/api/v1/shares":
+			body, _ := ioutil.ReadAll(r.Body)
+			shared = string(body)
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><statuscode>200</statuscode></meta><data><id>7</id></data></ocs>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	err = c.AttachFileToCard(1, 2, "Test/test.txt")
+	t.Equal(ErrDeckNotInstalled, err)
+
+	deck = true
+	err = c.AttachFileToCard(1, 2, "Test/test.txt")
+	t.Nil(err)
+	t.Equal("path=Test%2Ftest.txt&shareType=12&shareWith=2", shared)
+
+	err = c.AttachFileToCard(3, 2, "Test/test.txt")
+	t.NotNil(err)
+}
@@ -0,0 +1,175 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"sync"
+)
+
+// ErrRootPath is returned when asked to delete the root folder of
+// the user, which would wipe the whole account.
+var ErrRootPath = errors.New("refusing to delete the root folder")
+
+// isRoot reports whether p is the root folder of the user.
+func isRoot(p string) bool {
+	return path.Clean("/"+p) == "/"
+}
+
+// DeleteIfExists removes the file or folder at p and reports whether
+// it existed. Unlike Delete, a missing resource is not an error.
+func (c *Client) DeleteIfExists(p string) (bool, error) {
+	if isRoot(p) {
+		return false, ErrRootPath
+	}
+
+	req, err := c.newWebDavRequest("DELETE", p, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode == http.StatusLocked:
+		return false, ErrLocked
+	case resp.StatusCode/100 != 2:
+		return false, newStatusError(resp)
+	}
+	return true, nil
+}
+
+// DeleteTree removes the folder at path and its whole content. Files
+// are deleted concurrently by the given number of workers, then the
+// emptied folders are deleted bottom-up. Some servers time out when a
+// huge tree is deleted with a single request, which doesn't happen
+// here. If some file can't be deleted, the folders are left in place
+// and the first error is returned. DeleteTreeWithProgress reports the
+// progress of the deletion.
+func (c *Client) DeleteTree(path string, workers int) error {
+	return c.DeleteTreeWithProgress(path, workers, nil)
+}
+
+// DeleteTreeWithProgress is like DeleteTree, calling progress with the
+// number of files and folders deleted out of the total after each
+// deletion, see ProgressFunc.
+func (c *Client) DeleteTreeWithProgress(path string, workers int, progress ProgressFunc) error {
+	summary := c.startDeleteTree(context.Background(), path, workers, progress).Wait()
+	return summary.Err()
+}
+
+// StartDeleteTree starts deleting the folder at p like DeleteTree
+// and returns immediately. The returned batch can be cancelled, e.g.
+// on shutdown, and waited for; its summary lists the paths deleted,
+// failed and skipped because of the cancellation.
+func (c *Client) StartDeleteTree(ctx context.Context, p string, workers int) *Batch {
+	return c.startDeleteTree(ctx, p, workers, nil)
+}
+
+// startDeleteTree is like StartDeleteTree, calling progress, if not
+// nil, after each deletion.
+func (c *Client) startDeleteTree(ctx context.Context, p string, workers int, progress ProgressFunc) *Batch {
+	b := NewBatch(ctx, workers)
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+
+		root := path.Clean("/" + p)
+		if isRoot(root) {
+			b.record(root, ErrRootPath)
+			return
+		}
+		files, dirs, err := c.walk(root)
+		if err != nil {
+			b.record(root, err)
+			return
+		}
+
+		// remove deletes p and reports the progress, one call at
+		// a time.
+		var (
+			mu      sync.Mutex
+			deleted int64
+		)
+		total := int64(len(files) + len(dirs) + 1)
+		remove := func(ctx context.Context, p string) error {
+			err := c.delete(ctx, p)
+			if err == nil && progress != nil {
+				mu.Lock()
+				deleted++
+				progress(deleted, total)
+				mu.Unlock()
+			}
+			return err
+		}
+
+		var pending sync.WaitGroup
+		for _, file := range files {
+			file := file
+			pending.Add(1)
+			started := b.Go(file, func(ctx context.Context) error {
+				defer pending.Done()
+				return remove(ctx, file)
+			})
+			if !started {
+				pending.Done()
+			}
+		}
+		pending.Wait()
+
+		b.mu.Lock()
+		failed := len(b.summary.Failed) > 0
+		b.mu.Unlock()
+		if failed {
+			return
+		}
+
+		for i := len(dirs) - 1; i >= 0; i-- {
+			dir := dirs[i]
+			err := b.run(dir, func(ctx context.Context) error {
+				return remove(ctx, dir)
+			})
+			if err != nil {
+				return
+			}
+		}
+		b.run(root, func(ctx context.Context) error {
+			return remove(ctx, root)
+		})
+	}()
+
+	return b
+}
+
+// delete removes the resource at p. A missing resource is not an
+// error.
+func (c *Client) delete(ctx context.Context, p string) error {
+	return c.deleteDav(ctx, path.Join(c.webDAVRoot(), p))
+}
+
+// deleteDav is like delete, for a path relative to the server URL.
+func (c *Client) deleteDav(ctx context.Context, davPath string) error {
+	req, err := c.newRequestContext(ctx, "DELETE", davPath, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return contextErr(ctx, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		return newStatusError(resp)
+	}
+
+	return nil
+}
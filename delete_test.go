@@ -0,0 +1,83 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+)
+
+func (t *testSuite) TestDeleteTree() {
+	s := newDavServer()
+	defer s.Close()
+
+	s.dirs["/Test"] = true
+	s.dirs["/Test/Folder"] = true
+	s.dirs["/Test/Folder/Empty"] = true
+	s.dirs["/Other"] = true
+	s.files["/Test/a.txt"] = []byte("a")
+	s.files["/Test/Folder/b.txt"] = []byte("b")
+	s.files["/Test/Folder/c d.txt"] = []byte("c")
+	s.files["/Other/e.txt"] = []byte("e")
+
+	var progress []string
+	err := s.client().DeleteTreeWithProgress("Test", 2, func(deleted, total int64) {
+		progress = append(progress, fmt.Sprintf("%d/%d", deleted, total))
+	})
+	t.Nil(err)
+	t.Equal(1, len(s.files))
+	t.Equal(map[string]bool{"/": true, "/Other": true}, s.dirs)
+	t.Equal([]string{"1/6", "2/6", "3/6", "4/6", "5/6", "6/6"}, progress)
+}
+
+func (t *testSuite) TestStartDeleteTreeCancel() {
+	s := newDavServer()
+	defer s.Close()
+
+	s.dirs["/Test"] = true
+	s.files["/Test/a.txt"] = []byte("a")
+	s.files["/Test/b.txt"] = []byte("b")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	summary := s.client().StartDeleteTree(ctx, "Test", 2).Wait()
+	t.Equal(0, len(summary.Completed))
+	t.Equal([]string{"/Test/a.txt", "/Test/b.txt", "/Test"}, summary.Cancelled)
+	t.Nil(summary.Err())
+	t.Equal(2, len(s.files))
+
+	summary = s.client().StartDeleteTree(context.Background(), "Test", 2).Wait()
+	t.Equal([]string{"/Test"}, summary.Completed[2:])
+	t.Equal(0, len(s.files))
+}
+
+func (t *testSuite) TestDeleteIfExists() {
+	s := newDavServer()
+	defer s.Close()
+	s.dirs["/Test"] = true
+	s.files["/Test/a.txt"] = []byte("a")
+	c := s.client()
+
+	deleted, err := c.DeleteIfExists("Test/a.txt")
+	t.Nil(err)
+	t.True(deleted)
+	t.Nil(s.files["/Test/a.txt"])
+
+	deleted, err = c.DeleteIfExists("Test/a.txt")
+	t.Nil(err)
+	t.False(deleted)
+}
+
+func (t *testSuite) TestDeleteRoot() {
+	s := newDavServer()
+	defer s.Close()
+	s.files["/a.txt"] = []byte("a")
+	c := s.client()
+
+	for _, p := range []string{"", "/", ".", "a/..", "//"} {
+		t.Equal(ErrRootPath, c.Delete(p))
+		_, err := c.DeleteIfExists(p)
+		t.Equal(ErrRootPath, err)
+		t.Equal(ErrRootPath, c.DeleteTree(p, 1))
+	}
+	t.Nil(s.requests)
+	t.True(s.dirs["/"])
+}
@@ -0,0 +1,30 @@
+package cloud
+
+import (
+	"errors"
+	"net/url"
+)
+
+// DirectDownloadURL returns a direct link to the file at path, which
+// can be handed to another service to download the file without
+// credentials, instead of streaming it through the caller. The link
+// is short-lived: Nextcloud expires it after 8 hours and doesn't
+// report the expiration. It requires Nextcloud 14 or later.
+func (c *Client) DirectDownloadURL(path string) (string, error) {
+	fileId, err := c.fileId(path)
+	if err != nil {
+		return "", err
+	}
+
+	result := struct {
+		Url string `xml:"url" json:"url"`
+	}{}
+	err = c.sendOCS("POST", "apps/dav/api/v1/direct", url.Values{"fileId": {fileId}}, &result)
+	if err != nil {
+		return "", err
+	}
+	if result.Url == "" {
+		return "", errors.New("no direct link returned by the server")
+	}
+	return result.Url, nil
+}
@@ -0,0 +1,34 @@
+package cloud
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+func (t *testSuite) TestDirectDownloadURL() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "PROPFIND" && r.URL.Path == "/remote.php/webdav/Test/test.txt":
+			w.WriteHeader(http.StatusMultiStatus)
+			fmt.Fprint(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns"><d:response><d:href>/remote.php/webdav/Test/test.txt</d:href><d:propstat><d:prop><oc:fileid>42</oc:fileid></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response></d:multistatus>`)
+		case r.Method == "POST" && r.URL.Path == "/ocs/v2.php/apps/dav/api/v1/direct":
+			t.Equal("42", r.FormValue("fileId"))
+			t.Equal("true", r.Header.Get("OCS-APIRequest"))
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data><url>https://cloud.example.com/remote.php/direct/abc</url></data></ocs>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	link, err := c.DirectDownloadURL("Test/test.txt")
+	t.Nil(err)
+	t.Equal("https://cloud.example.com/remote.php/direct/abc", link)
+
+	_, err = c.DirectDownloadURL("Test/missing.txt")
+	t.NotNil(err)
+}
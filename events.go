@@ -0,0 +1,46 @@
+package cloud
+
+import "time"
+
+// Operations reported by events.
+const (
+	OpUpload   = "upload"
+	OpDownload = "download"
+)
+
+// Event describes a completed transfer, see Client.Events.
+type Event struct {
+	// Op is the kind of transfer, OpUpload or OpDownload.
+	Op string
+
+	// Path is the remote path of the transferred file.
+	Path string
+
+	// Bytes is the number of bytes transferred.
+	Bytes int64
+
+	// Duration is the time spent by the transfer, retries
+	// included.
+	Duration time.Duration
+
+	// Err is the error which made the transfer fail, if any.
+	Err error
+}
+
+// emit sends an event to c.Events without blocking.
+func (c *Client) emit(op string, path string, bytes int64, start time.Time, err error) {
+	if c.Events == nil {
+		return
+	}
+	event := Event{
+		Op:       op,
+		Path:     path,
+		Bytes:    bytes,
+		Duration: time.Since(start),
+		Err:      err,
+	}
+	select {
+	case c.Events <- event:
+	default:
+	}
+}
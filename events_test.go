@@ -0,0 +1,71 @@
+package cloud
+
+func (t *testSuite) TestEvents() {
+	s := newDavServer()
+	defer s.Close()
+
+	events := make(chan Event, 2)
+	c := s.client()
+	c.Events = events
+
+	err := c.Upload([]byte("Hello World!\n"), "test.txt")
+	t.Nil(err)
+	_, err = c.Download("test.txt")
+	t.Nil(err)
+
+	// The channel is full: the event is dropped without blocking.
+	_, err = c.Download("test.txt")
+	t.Nil(err)
+
+	event := <-events
+	t.Equal(OpUpload, event.Op)
+	t.Equal("test.txt", event.Path)
+	t.Equal(int64(13), event.Bytes)
+	t.Nil(event.Err)
+
+	event = <-events
+	t.Equal(OpDownload, event.Op)
+	t.Equal(int64(13), event.Bytes)
+
+	t.Equal(0, len(events))
+}
+
+func (t *testSuite) TestEventsEveryTransfer() {
+	s := newDavServer()
+	defer s.Close()
+	dest := newDavServer()
+	defer dest.Close()
+
+	events := make(chan Event, 10)
+	c := s.client()
+	c.Events = events
+	d := dest.client()
+	d.Events = events
+
+	t.Nil(c.WriteText("test.txt", "Hello World!\n"))
+	_, err := c.DownloadRange("test.txt", 6, 5)
+	t.Nil(err)
+	_, etag, _, err := c.DownloadIfChanged("test.txt", "")
+	t.Nil(err)
+	_, _, _, err = c.DownloadIfChanged("test.txt", etag)
+	t.Nil(err)
+	_, _, _, err = c.DownloadIfChanged("missing.txt", "")
+	t.NotNil(err)
+	t.Nil(c.Transfer(d, "test.txt", "test.txt"))
+
+	expected := []Event{
+		{Op: OpUpload, Path: "test.txt", Bytes: 13},
+		{Op: OpDownload, Path: "test.txt", Bytes: 5},
+		{Op: OpDownload, Path: "test.txt", Bytes: 13},
+		{Op: OpDownload, Path: "test.txt"},
+		{Op: OpDownload, Path: "missing.txt", Err: err},
+		{Op: OpDownload, Path: "test.txt", Bytes: 13},
+		{Op: OpUpload, Path: "test.txt", Bytes: 13},
+	}
+	t.Equal(len(expected), len(events))
+	for _, e := range expected {
+		event := <-events
+		event.Duration = 0
+		t.Equal(e, event)
+	}
+}
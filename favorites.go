@@ -0,0 +1,18 @@
+package cloud
+
+// Favorite marks the file or folder at path as a favorite of the
+// user.
+func (c *Client) Favorite(path string) error {
+	return c.SetProperty(path, "http://owncloud.org/ns", "favorite", "1")
+}
+
+// Unfavorite removes the file or folder at path from the favorites
+// of the user.
+func (c *Client) Unfavorite(path string) error {
+	return c.SetProperty(path, "http://owncloud.org/ns", "favorite", "0")
+}
+
+// ListFavorites returns the favorite files and folders of the user.
+func (c *Client) ListFavorites() ([]FileInfo, error) {
+	return c.filterFiles("<oc:favorite>1</oc:favorite>")
+}
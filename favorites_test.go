@@ -0,0 +1,56 @@
+package cloud
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+func (t *testSuite) TestFavorites() {
+	var mu sync.Mutex
+	favorites := map[string]bool{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		data, _ := ioutil.ReadAll(r.Body)
+		body := string(data)
+
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprint(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns">`)
+		switch r.Method {
+		case "PROPPATCH":
+			name := strings.TrimPrefix(r.URL.Path, "/remote.php/webdav")
+			favorites[name] = strings.Contains(body, "<x:favorite>1</x:favorite>")
+			fmt.Fprintf(w, `<d:response><d:href>%s</d:href><d:propstat><d:prop><oc:favorite/></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`, r.URL.Path)
+		case "REPORT":
+			t.Equal("/remote.php/dav/files/admin", r.URL.Path)
+			t.True(strings.Contains(body, "<oc:filter-rules><oc:favorite>1</oc:favorite></oc:filter-rules>"))
+			for name, favorite := range favorites {
+				if favorite {
+					fmt.Fprintf(w, `<d:response><d:href>/remote.php/dav/files/admin%s</d:href><d:propstat><d:prop><d:resourcetype/></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`, name)
+				}
+			}
+		}
+		fmt.Fprint(w, `</d:multistatus>`)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	t.Nil(c.Favorite("Test/test.txt"))
+	files, err := c.ListFavorites()
+	t.Nil(err)
+	t.Equal(1, len(files))
+	if len(files) == 1 {
+		t.Equal("/Test/test.txt", files[0].Path)
+	}
+
+	t.Nil(c.Unfavorite("Test/test.txt"))
+	files, err = c.ListFavorites()
+	t.Nil(err)
+	t.Equal(0, len(files))
+}
@@ -0,0 +1,109 @@
+package cloud
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrFederationDisabled is returned by ShareWithRemote when the server
+// doesn't allow sharing with other servers, because the Federation
+// app is disabled or outgoing federated shares are turned off.
+var ErrFederationDisabled = errors.New("federated sharing is not enabled on the server")
+
+// pendingShare is a federated share awaiting for the user to accept
+// it, as returned by the remote shares API.
+type pendingShare struct {
+	Id         ocsUint `xml:"id" json:"id"`
+	Remote     string  `xml:"remote" json:"remote"`
+	ShareToken string  `xml:"share_token" json:"share_token"`
+	Name       string  `xml:"name" json:"name"`
+	Owner      string  `xml:"owner" json:"owner"`
+	User       string  `xml:"user" json:"user"`
+}
+
+// ListPendingShares returns the federated shares from other servers
+// which the user didn't accept or decline yet. Their Remote field
+// holds the originating server and Path the name of the shared item.
+func (c *Client) ListPendingShares() ([]Share, error) {
+	result := struct {
+		Elements []pendingShare `xml:"element" json:"element"`
+	}{}
+	err := c.sendOCS("GET", "apps/files_sharing/api/v1/remote_shares/pending", nil, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make([]Share, len(result.Elements))
+	for i, pending := range result.Elements {
+		shares[i] = Share{
+			Id:        uint(pending.Id),
+			ShareType: ShareTypeFederated,
+			UidOwner:  pending.Owner,
+			Path:      pending.Name,
+			ShareWith: pending.User,
+			Token:     pending.ShareToken,
+			Remote:    pending.Remote,
+		}
+	}
+
+	return shares, nil
+}
+
+// AcceptRemoteShare accepts the pending federated share with the
+// given id.
+func (c *Client) AcceptRemoteShare(id uint) error {
+	return c.sendOCS("POST", fmt.Sprintf("apps/files_sharing/api/v1/remote_shares/pending/%d", id), nil, nil)
+}
+
+// DeclineRemoteShare declines the pending federated share with the
+// given id.
+func (c *Client) DeclineRemoteShare(id uint) error {
+	return c.sendOCS("DELETE", fmt.Sprintf("apps/files_sharing/api/v1/remote_shares/pending/%d", id), nil, nil)
+}
+
+// ShareWithRemote shares path with a user of another server, granting
+// the given permissions. remoteUser is the federated cloud id of the
+// recipient, e.g. "bob@cloud.example.com".
+func (c *Client) ShareWithRemote(path, remoteUser string, permissions Permission) (*Share, error) {
+	if cloudIdRemote(remoteUser) == "" {
+		return nil, fmt.Errorf("invalid federated cloud id %q", remoteUser)
+	}
+	capabilities, err := c.Capabilities()
+	if err != nil {
+		return nil, err
+	}
+	if !capabilities.Enabled("files_sharing", "federation", "outgoing") {
+		return nil, ErrFederationDisabled
+	}
+
+	share, err := c.CreateShare(path, ShareOptions{
+		ShareType:   ShareTypeFederated,
+		ShareWith:   remoteUser,
+		Permissions: permissions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	setRemote(share)
+	return share, nil
+}
+
+// cloudIdRemote returns the server of the federated cloud id, e.g.
+// "cloud.example.com" for "bob@cloud.example.com", or "" if id is
+// not a cloud id. User names may contain "@", servers can't.
+func cloudIdRemote(id string) string {
+	i := strings.LastIndex(id, "@")
+	if i <= 0 {
+		return ""
+	}
+	return id[i+1:]
+}
+
+// setRemote sets the Remote field of a federated share created by
+// the user to the server of its recipient.
+func setRemote(share *Share) {
+	if share.ShareType == ShareTypeFederated && share.Remote == "" {
+		share.Remote = cloudIdRemote(share.ShareWith)
+	}
+}
@@ -0,0 +1,89 @@
+package cloud
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+)
+
+func (t *testSuite) TestListPendingShares() {
+	ts := newOCSServer(map[string]string{
+		"/ocs/v2.php/apps/files_sharing/api/v1/remote_shares/pending": `
+<element><id>3</id><remote>https://other.example.com</remote><remote_id>12</remote_id><share_token>abc</share_token><name>/Reports</name><owner>alice</owner><user>admin</user><mountpoint>{{TemporaryMountPointName#/Reports}}</mountpoint><accepted>0</accepted></element>`,
+		"/ocs/v2.php/apps/files_sharing/api/v1/remote_shares/pending/3": ``,
+	})
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	shares, err := c.ListPendingShares()
+	t.Nil(err)
+	t.Equal([]Share{{
+		Id:        3,
+		ShareType: ShareTypeFederated,
+		UidOwner:  "alice",
+		Path:      "/Reports",
+		ShareWith: "admin",
+		Token:     "abc",
+		Remote:    "https://other.example.com",
+	}}, shares)
+
+	t.Nil(c.AcceptRemoteShare(3))
+	t.Nil(c.DeclineRemoteShare(3))
+	t.NotNil(c.AcceptRemoteShare(4))
+}
+
+func (t *testSuite) TestShareWithRemote() {
+	var federation bool
+	var form url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/ocs/v2.php/cloud/capabilities":
+			fmt.Fprintf(w, `<?xml version="1.0"?><ocs><meta><statuscode>200</statuscode></meta><data><capabilities><files_sharing><federation><outgoing>%t</outgoing><incoming>true</incoming></federation></files_sharing></capabilities></data></ocs>`, federation)
+		case r.Method == "POST":
+			r.ParseForm()
+			form = r.PostForm
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data><id>5</id><share_type>6</share_type><permissions>1</permissions><path>/Reports</path><share_with>bob@other.example.com</share_with></data></ocs>`)
+		default:
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data>
+<element><id>5</id><share_type>6</share_type><permissions>1</permissions><path>/Reports</path><share_with>bob@mail.example.com@other.example.com</share_with></element>
+<element><id>6</id><share_type>0</share_type><permissions>1</permissions><path>/Reports</path><share_with>carol</share_with></element>
+</data></ocs>`)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	_, err = c.ShareWithRemote("Reports", "bob@other.example.com", PermissionRead)
+	t.Equal(ErrFederationDisabled, err)
+	_, err = c.ShareWithRemote("Reports", "bob", PermissionRead)
+	t.NotNil(err)
+	t.Nil(form)
+
+	federation = true
+	share, err := c.ShareWithRemote("Reports", "bob@other.example.com", PermissionRead)
+	t.Nil(err)
+	t.Equal(url.Values{
+		"path":        {"Reports"},
+		"shareType":   {"6"},
+		"shareWith":   {"bob@other.example.com"},
+		"permissions": {"1"},
+	}, form)
+	if share != nil {
+		t.Equal(uint(5), share.Id)
+		t.Equal("other.example.com", share.Remote)
+	}
+
+	shares, err := c.ListShares()
+	t.Nil(err)
+	t.Equal(2, len(shares))
+	if len(shares) == 2 {
+		t.Equal(ShareTypeFederated, shares[0].ShareType)
+		t.Equal("other.example.com", shares[0].Remote)
+		t.Equal("", shares[1].Remote)
+	}
+}
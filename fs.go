@@ -0,0 +1,163 @@
+package cloud
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+)
+
+// FileSystem returns the files of c as an fs.FS, which also
+// implements fs.ReadDirFS and fs.StatFS. This lets the standard
+// library, e.g. fs.WalkDir, http.FS or template.ParseFS, operate on
+// the cloud. The content of a file is streamed as it is read.
+func FileSystem(c *Client) fs.FS {
+	return fileSystem{c}
+}
+
+type fileSystem struct {
+	c *Client
+}
+
+func (fsys fileSystem) Open(name string) (fs.File, error) {
+	info, err := fsys.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir {
+		return &dirFile{fsys: fsys, name: name, info: info}, nil
+	}
+
+	req, err := fsys.c.newWebDavRequest("GET", name, nil)
+	if err != nil {
+		return nil, pathError("open", name, err)
+	}
+	resp, err := fsys.c.do(req)
+	if err != nil {
+		return nil, pathError("open", name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, pathError("open", name, newStatusError(resp))
+	}
+
+	return &file{info: info, body: resp.Body}, nil
+}
+
+func (fsys fileSystem) Stat(name string) (fs.FileInfo, error) {
+	info, err := fsys.stat("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return info.fsInfo(), nil
+}
+
+func (fsys fileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, pathError("readdir", name, fs.ErrInvalid)
+	}
+	entries, err := fsys.c.ReadDir(name)
+	if err != nil {
+		return nil, pathError("readdir", name, err)
+	}
+
+	dirEntries := make([]fs.DirEntry, len(entries))
+	for i := range entries {
+		dirEntries[i] = entries[i]
+	}
+	return dirEntries, nil
+}
+
+// stat returns the description of the file name, or an error for the
+// operation op.
+func (fsys fileSystem) stat(op, name string) (*FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, pathError(op, name, fs.ErrInvalid)
+	}
+	info, err := fsys.c.Stat(name)
+	if err != nil {
+		return nil, pathError(op, name, err)
+	}
+	if name == "." {
+		info.Name = "."
+	}
+	return info, nil
+}
+
+// pathError returns err as the error of the operation op on the file
+// name, as expected from an fs.FS.
+func pathError(op, name string, err error) error {
+	if statusErr, ok := err.(*StatusError); ok && statusErr.StatusCode == http.StatusNotFound {
+		err = fs.ErrNotExist
+	}
+	if err == ErrNotFound {
+		err = fs.ErrNotExist
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// file is a file opened by fileSystem.
+type file struct {
+	info *FileInfo
+	body io.ReadCloser
+}
+
+func (f *file) Stat() (fs.FileInfo, error) {
+	return f.info.fsInfo(), nil
+}
+
+func (f *file) Read(p []byte) (int, error) {
+	return f.body.Read(p)
+}
+
+func (f *file) Close() error {
+	return f.body.Close()
+}
+
+// dirFile is a folder opened by fileSystem. Its entries are read on
+// the first call of ReadDir.
+type dirFile struct {
+	fsys    fileSystem
+	name    string
+	info    *FileInfo
+	entries []fs.DirEntry
+	read    bool
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) {
+	return d.info.fsInfo(), nil
+}
+
+func (d *dirFile) Read(p []byte) (int, error) {
+	return 0, pathError("read", d.name, fs.ErrInvalid)
+}
+
+func (d *dirFile) Close() error {
+	return nil
+}
+
+// ReadDir returns the next n entries of the folder, or all the
+// remaining ones if n <= 0, as specified by fs.ReadDirFile.
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		entries, err := d.fsys.ReadDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries, d.read = entries, true
+	}
+
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
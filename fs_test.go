@@ -0,0 +1,35 @@
+package cloud
+
+import (
+	"errors"
+	"io/fs"
+	"testing/fstest"
+)
+
+func (t *testSuite) TestFileSystem() {
+	s := newDavServer()
+	defer s.Close()
+	c := s.client()
+	_, err := c.UploadTree(testDir, "testdata")
+	t.Nil(err)
+
+	fsys := FileSystem(c)
+	t.Nil(fstest.TestFS(fsys, "testdata/test.txt", "testdata/Folder/test.txt"))
+
+	data, err := fs.ReadFile(fsys, "testdata/Folder/test.txt")
+	t.Nil(err)
+	t.Equal("Hello World!\n", string(data))
+
+	var walked []string
+	err = fs.WalkDir(fsys, "testdata", func(p string, d fs.DirEntry, err error) error {
+		walked = append(walked, p)
+		return err
+	})
+	t.Nil(err)
+	t.Equal([]string{"testdata", "testdata/Folder", "testdata/Folder/test.txt", "testdata/test.txt"}, walked)
+
+	_, err = fsys.Open("missing.txt")
+	t.True(errors.Is(err, fs.ErrNotExist))
+	_, err = fsys.Open("../test.txt")
+	t.True(errors.Is(err, fs.ErrInvalid))
+}
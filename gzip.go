@@ -0,0 +1,57 @@
+package cloud
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"time"
+)
+
+// UploadGzip compresses src with gzip and uploads it to dest,
+// setting the Content-Encoding: gzip header.
+//
+// The server doesn't decode the request body itself: unless a
+// reverse proxy in front of it decompresses the uploads, the gzip
+// data is what lands on disk. DownloadGzip reads back such files
+// transparently.
+func (c *Client) UploadGzip(src []byte, dest string) error {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(src); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	req, err := c.newWebDavRequest("PUT", dest, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "gzip")
+	err = c.sendUpload(req, nil)
+	c.emit(OpUpload, dest, int64(buf.Len()), start, err)
+	return err
+}
+
+// DownloadGzip downloads the file at path, decompressing it if it
+// was stored gzip compressed, e.g. by UploadGzip.
+func (c *Client) DownloadGzip(path string) ([]byte, error) {
+	data, err := c.Download(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		return data, nil
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return ioutil.ReadAll(r)
+}
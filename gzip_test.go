@@ -0,0 +1,22 @@
+package cloud
+
+func (t *testSuite) TestUploadGzip() {
+	s := newDavServer()
+	defer s.Close()
+
+	c := s.client()
+	err := c.UploadGzip([]byte("Hello World!\n"), "test.txt.gz")
+	t.Nil(err)
+
+	// The server stores the compressed data as is.
+	t.Equal(byte(0x1f), s.files["/test.txt.gz"][0])
+
+	data, err := c.DownloadGzip("test.txt.gz")
+	t.Nil(err)
+	t.Equal("Hello World!\n", string(data))
+
+	s.files["/test.txt"] = []byte("Hello World!\n")
+	data, err = c.DownloadGzip("test.txt")
+	t.Nil(err)
+	t.Equal("Hello World!\n", string(data))
+}
@@ -0,0 +1,115 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrLocked is returned when the target of a request is locked,
+// e.g. because the file is being edited in the web interface.
+var ErrLocked = errors.New("resource is locked")
+
+// LockRetry configures how uploads wait for a locked file to be
+// released. Locks are usually held for a short time, so waiting a
+// bit is often enough for the upload to succeed. If both MaxAttempts
+// and MaxWait are zero, uploads are not retried.
+type LockRetry struct {
+	// MaxAttempts is the maximum number of attempts, the first
+	// one included. Zero means no limit besides MaxWait.
+	MaxAttempts int
+
+	// MaxWait bounds the total time spent waiting. Zero means no
+	// limit besides MaxAttempts.
+	MaxWait time.Duration
+
+	// Backoff is the wait before the first retry, doubled at each
+	// further attempt. Zero means one second.
+	Backoff time.Duration
+}
+
+// sendUpload sends req, which uploads content to a file, e.g. a PUT
+// or the MOVE assembling a chunked upload. A locked destination is
+// reported as ErrLocked, after waiting for it to be released as
+// configured by c.LockRetry: the request is sent again with the body
+// returned by req.GetBody, and only once if it has none. check, if
+// not nil, is called first to map the responses specific to req to
+// an error; the other non-2xx responses are a *StatusError.
+func (c *Client) sendUpload(req *http.Request, check func(*http.Response) error) error {
+	attempt := req
+	return c.retryLocked(req.Context(), func() error {
+		if attempt == nil {
+			if req.Body != nil && req.GetBody == nil {
+				return ErrLocked
+			}
+			attempt = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return err
+				}
+				attempt.Body = body
+			}
+		}
+
+		resp, err := c.do(attempt)
+		attempt = nil
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if check != nil {
+			if err := check(resp); err != nil {
+				return err
+			}
+		}
+		switch {
+		case resp.StatusCode == http.StatusLocked:
+			return ErrLocked
+		case resp.StatusCode/100 != 2:
+			return newStatusError(resp)
+		}
+		return nil
+	})
+}
+
+// retryLocked calls f, retrying it as configured by c.LockRetry as
+// long as it returns ErrLocked. It stops waiting and returns
+// ctx.Err() when ctx is done.
+func (c *Client) retryLocked(ctx context.Context, f func() error) error {
+	err := f()
+	policy := c.LockRetry
+	if policy == nil || err != ErrLocked {
+		return err
+	}
+
+	if policy.MaxAttempts == 0 && policy.MaxWait == 0 {
+		return err
+	}
+
+	backoff := policy.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	var waited time.Duration
+	for attempt := 1; err == ErrLocked; attempt++ {
+		if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+			break
+		}
+		if policy.MaxWait > 0 && waited+backoff > policy.MaxWait {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		waited += backoff
+		backoff *= 2
+		err = f()
+	}
+
+	return err
+}
@@ -0,0 +1,99 @@
+package cloud
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func (t *testSuite) TestUploadLockRetry() {
+	locked := 2
+	var attempts int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts <= locked {
+			w.WriteHeader(http.StatusLocked)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	err = c.Upload([]byte("Hello World!\n"), "Test/test.txt")
+	t.Equal(ErrLocked, err)
+	t.Equal(1, attempts)
+
+	attempts = 0
+	c.LockRetry = &LockRetry{MaxAttempts: 3, Backoff: time.Millisecond}
+	err = c.Upload([]byte("Hello World!\n"), "Test/test.txt")
+	t.Nil(err)
+	t.Equal(3, attempts)
+
+	attempts = 0
+	locked = 5
+	err = c.Upload([]byte("Hello World!\n"), "Test/test.txt")
+	t.Equal(ErrLocked, err)
+	t.Equal(3, attempts)
+}
+
+func (t *testSuite) TestUploadsLockRetry() {
+	var attempts int
+	var content string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts%2 == 1 {
+			w.WriteHeader(http.StatusLocked)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		content = string(data)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+	c.LockRetry = &LockRetry{MaxAttempts: 2, Backoff: time.Millisecond}
+
+	dir, err := ioutil.TempDir("", "cloud")
+	t.Nil(err)
+	defer os.RemoveAll(dir)
+	local := filepath.Join(dir, "test.txt")
+	t.Nil(ioutil.WriteFile(local, []byte("file"), 0644))
+
+	uploads := []struct {
+		content string
+		upload  func() error
+	}{
+		{"file", func() error { return c.UploadFile(local, "Test/test.txt") }},
+		{"text", func() error { return c.WriteText("Test/test.txt", "text") }},
+		{"match", func() error { return c.UploadIfMatch([]byte("match"), "Test/test.txt", "abc") }},
+		{"checksum", func() error { return c.UploadWithChecksum("Test/test.txt", []byte("checksum"), "MD5") }},
+	}
+	for _, u := range uploads {
+		attempts = 0
+		t.Nil(u.upload())
+		t.Equal(2, attempts)
+		t.Equal(u.content, content)
+	}
+	attempts = 0
+	t.Nil(c.UploadGzip([]byte("gzip"), "Test/test.txt"))
+	t.Equal(2, attempts)
+
+	// A stream can't be read again.
+	attempts = 0
+	err = c.UploadFrom("Test/test.txt", strings.NewReader("stream"))
+	t.Nil(err)
+	t.Equal(2, attempts)
+	attempts = 0
+	err = c.UploadFrom("Test/test.txt", ioutil.NopCloser(strings.NewReader("stream")))
+	t.Equal(ErrLocked, err)
+	t.Equal(1, attempts)
+}
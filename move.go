@@ -0,0 +1,92 @@
+package cloud
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// ErrPreconditionFailed is returned when the server rejects a
+// conditional request, e.g. because the resource changed.
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// Move moves or renames src to dest. If dest already exists, the
+// server refuses the move and ErrPreconditionFailed is returned.
+func (c *Client) Move(src, dest string) error {
+	return c.relocate("MOVE", src, dest, false, nil)
+}
+
+// MoveOverwrite is like Move but replaces dest if it exists.
+func (c *Client) MoveOverwrite(src, dest string) error {
+	return c.relocate("MOVE", src, dest, true, nil)
+}
+
+// Rename renames the file or folder p to newName, in the same
+// folder. newName can't contain a slash: use Move to move p to
+// another folder.
+func (c *Client) Rename(p, newName string) error {
+	if newName == "" || newName == "." || newName == ".." || strings.Contains(newName, "/") {
+		return fmt.Errorf("invalid name %q", newName)
+	}
+	return c.Move(p, path.Join(path.Dir(path.Clean("/"+p)), newName))
+}
+
+// MoveIfMatch moves src to dest only if the ETag of src is still
+// etag, as returned by a previous listing. Otherwise the server
+// refuses the move and ErrPreconditionFailed is returned. This lets
+// a worker claim a file by moving it only if it is the version it
+// inspected. An existing dest is never overwritten.
+func (c *Client) MoveIfMatch(src, dest, etag string) error {
+	header := http.Header{}
+	header.Set("If", "(["+quoteETag(etag)+"])")
+	return c.relocate("MOVE", src, dest, false, header)
+}
+
+// Copy copies src to dest on the server, along with its content if
+// src is a folder. If dest already exists, the server refuses the
+// copy and ErrPreconditionFailed is returned.
+func (c *Client) Copy(src, dest string) error {
+	return c.relocate("COPY", src, dest, false, nil)
+}
+
+// CopyOverwrite is like Copy but replaces dest if it exists.
+func (c *Client) CopyOverwrite(src, dest string) error {
+	return c.relocate("COPY", src, dest, true, nil)
+}
+
+// relocate sends a MOVE or COPY request for src to dest with the
+// given extra headers.
+func (c *Client) relocate(method, src, dest string, overwrite bool, header http.Header) error {
+	req, err := c.newWebDavRequest(method, src, nil)
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Destination", c.WebDAVURL(dest))
+	req.Header.Set("Depth", "infinity")
+	if overwrite {
+		req.Header.Set("Overwrite", "T")
+	} else {
+		req.Header.Set("Overwrite", "F")
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated, http.StatusNoContent:
+		return nil
+	case http.StatusPreconditionFailed:
+		return ErrPreconditionFailed
+	case http.StatusLocked:
+		return ErrLocked
+	}
+	return newStatusError(resp)
+}
@@ -0,0 +1,89 @@
+package cloud
+
+import (
+	"net/http"
+	"net/http/httptest"
+)
+
+func (t *testSuite) TestMoveIfMatch() {
+	etag := `"abc"`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Equal("MOVE", r.Method)
+		t.Equal("F", r.Header.Get("Overwrite"))
+		t.Equal("/remote.php/webdav/Inbox/test.txt", r.URL.Path)
+		t.Equal("http://"+r.Host+"/remote.php/webdav/Claimed/test.txt", r.Header.Get("Destination"))
+		if r.Header.Get("If") != "(["+etag+"])" {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	t.Nil(c.MoveIfMatch("Inbox/test.txt", "Claimed/test.txt", "abc"))
+	t.Nil(c.MoveIfMatch("Inbox/test.txt", "Claimed/test.txt", `"abc"`))
+	t.Equal(ErrPreconditionFailed, c.MoveIfMatch("Inbox/test.txt", "Claimed/test.txt", "def"))
+}
+
+func (t *testSuite) TestMove() {
+	s := newDavServer()
+	defer s.Close()
+	s.dirs["/Test"] = true
+	s.dirs["/Test/Old Folder"] = true
+	s.dirs["/Other"] = true
+	s.files["/Test/Old Folder/a.txt"] = []byte("a")
+	s.files["/Test/b.txt"] = []byte("b")
+	s.files["/Other/b.txt"] = []byte("other b")
+	c := s.client()
+
+	t.Nil(c.Move("Test/Old Folder", "Other/New Folder"))
+	t.Equal("a", string(s.files["/Other/New Folder/a.txt"]))
+	t.False(s.exists("/Test/Old Folder"))
+
+	t.Equal(ErrPreconditionFailed, c.Move("Test/b.txt", "Other/b.txt"))
+	t.Equal("other b", string(s.files["/Other/b.txt"]))
+
+	t.Nil(c.MoveOverwrite("Test/b.txt", "Other/b.txt"))
+	t.Equal("b", string(s.files["/Other/b.txt"]))
+	t.False(s.exists("/Test/b.txt"))
+}
+
+func (t *testSuite) TestCopy() {
+	s := newDavServer()
+	defer s.Close()
+	s.dirs["/Test"] = true
+	s.dirs["/Test/Folder"] = true
+	s.dirs["/Backup"] = true
+	s.files["/Test/Folder/Nested/a.txt"] = []byte("a")
+	s.dirs["/Test/Folder/Nested"] = true
+	c := s.client()
+
+	t.Nil(c.Copy("Test/Folder", "Backup/Folder"))
+	t.Equal("a", string(s.files["/Test/Folder/Nested/a.txt"]))
+	t.Equal("a", string(s.files["/Backup/Folder/Nested/a.txt"]))
+	t.True(s.dirs["/Backup/Folder/Nested"])
+
+	t.Equal(ErrPreconditionFailed, c.Copy("Test/Folder", "Backup/Folder"))
+	s.files["/Test/Folder/Nested/a.txt"] = []byte("new a")
+	t.Nil(c.CopyOverwrite("Test/Folder", "Backup/Folder"))
+	t.Equal("new a", string(s.files["/Backup/Folder/Nested/a.txt"]))
+}
+
+func (t *testSuite) TestRename() {
+	s := newDavServer()
+	defer s.Close()
+	s.dirs["/Test"] = true
+	s.files["/Test/a.txt"] = []byte("a")
+	c := s.client()
+
+	t.Nil(c.Rename("Test/a.txt", "b c.txt"))
+	t.Equal("a", string(s.files["/Test/b c.txt"]))
+	t.Nil(s.files["/Test/a.txt"])
+
+	t.NotNil(c.Rename("Test/b c.txt", "Other/b.txt"))
+	t.NotNil(c.Rename("Test/b c.txt", ".."))
+	t.True(s.files["/Test/b c.txt"] != nil)
+}
@@ -0,0 +1,87 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ErrNotificationsNotInstalled is returned by the notification
+// methods when the Notifications app is not enabled on the server.
+var ErrNotificationsNotInstalled = errors.New("the Notifications app is not installed")
+
+// Notification is a notification of the user.
+type Notification struct {
+	Id int `xml:"notification_id" json:"notification_id"`
+
+	// App is the app which published the notification, e.g.
+	// "files_sharing".
+	App     string `xml:"app" json:"app"`
+	Subject string `xml:"subject" json:"subject"`
+	Message string `xml:"message" json:"message"`
+
+	// Link is the URL the notification points to, if any.
+	Link string    `xml:"link" json:"link"`
+	Time time.Time `xml:"datetime" json:"datetime"`
+}
+
+// checkNotifications returns ErrNotificationsNotInstalled if the
+// Notifications app is not enabled.
+func (c *Client) checkNotifications() error {
+	ok, err := c.hasCapability("notifications")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotificationsNotInstalled
+	}
+	return nil
+}
+
+// ListNotifications returns the notifications of the user, newest
+// first.
+func (c *Client) ListNotifications() ([]Notification, error) {
+	if err := c.checkNotifications(); err != nil {
+		return nil, err
+	}
+
+	body, meta, err := c.sendOCSRequest(context.Background(), "GET", "ocs/v2.php/apps/notifications/api/v2/notifications", "")
+	if err != nil {
+		return nil, err
+	}
+	switch meta.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent:
+		// The server replies so when no app can notify the
+		// user.
+		return nil, nil
+	default:
+		return nil, meta.err()
+	}
+
+	result := struct {
+		Notifications []Notification `xml:"element" json:"element"`
+	}{}
+	if err := c.unmarshalOCS(body, &result); err != nil {
+		return nil, err
+	}
+	return result.Notifications, nil
+}
+
+// DismissNotification deletes the notification whose id is given.
+func (c *Client) DismissNotification(id int) error {
+	if err := c.checkNotifications(); err != nil {
+		return err
+	}
+	return c.sendOCS("DELETE", fmt.Sprintf("apps/notifications/api/v2/notifications/%d", id), nil, nil)
+}
+
+// DismissAllNotifications deletes all the notifications of the user.
+func (c *Client) DismissAllNotifications() error {
+	if err := c.checkNotifications(); err != nil {
+		return err
+	}
+	return c.sendOCS("DELETE", "apps/notifications/api/v2/notifications", nil, nil)
+}
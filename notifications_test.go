@@ -0,0 +1,66 @@
+package cloud
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+func (t *testSuite) TestNotifications() {
+	var installed bool
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ocs/v2.php/cloud/capabilities" {
+			capabilities := "<core><pollinterval>60</pollinterval></core>"
+			if installed {
+				capabilities += "<notifications><ocs-endpoints><element>list</element><element>delete</element></ocs-endpoints></notifications>"
+			}
+			fmt.Fprintf(w, `<?xml version="1.0"?><ocs><meta><statuscode>200</statuscode></meta><data><capabilities>%s</capabilities></data></ocs>`, capabilities)
+			return
+		}
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/ocs/v2.php/apps/notifications/api/v2/notifications":
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode><message>OK</message></meta><data>
+<element><notification_id>5</notification_id><app>files_sharing</app><user>admin</user><datetime>2023-11-14T22:13:20+00:00</datetime><object_type>remote_share</object_type><object_id>3</object_id><subject>bob shared a folder with you</subject><message></message><link>https://cloud.example.com/f/3</link><actions></actions></element>
+<element><notification_id>4</notification_id><app>updatenotification</app><user>admin</user><datetime>2023-11-13T10:00:00+00:00</datetime><subject>Update available</subject><message>Nextcloud 28.0.1 is available.</message><link></link></element>
+</data></ocs>`)
+		case r.Method == "DELETE":
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode><message>OK</message></meta><data/></ocs>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	_, err = c.ListNotifications()
+	t.Equal(ErrNotificationsNotInstalled, err)
+	t.Equal(ErrNotificationsNotInstalled, c.DismissNotification(5))
+	t.Equal(ErrNotificationsNotInstalled, c.DismissAllNotifications())
+	t.Nil(requests)
+
+	installed = true
+	notifications, err := c.ListNotifications()
+	t.Nil(err)
+	t.Equal(2, len(notifications))
+	if len(notifications) == 2 {
+		t.Equal(5, notifications[0].Id)
+		t.Equal("files_sharing", notifications[0].App)
+		t.Equal("bob shared a folder with you", notifications[0].Subject)
+		t.Equal("https://cloud.example.com/f/3", notifications[0].Link)
+		t.Equal(int64(1700000000), notifications[0].Time.Unix())
+		t.Equal(4, notifications[1].Id)
+		t.Equal("Nextcloud 28.0.1 is available.", notifications[1].Message)
+	}
+
+	t.Nil(c.DismissNotification(5))
+	t.Nil(c.DismissAllNotifications())
+	t.Equal([]string{
+		"GET /ocs/v2.php/apps/notifications/api/v2/notifications",
+		"DELETE /ocs/v2.php/apps/notifications/api/v2/notifications/5",
+		"DELETE /ocs/v2.php/apps/notifications/api/v2/notifications",
+	}, requests)
+}
@@ -0,0 +1,186 @@
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// OCSFormat is the format of the responses of the OCS API.
+type OCSFormat string
+
+const (
+	OCSFormatXML  OCSFormat = "xml"
+	OCSFormatJSON OCSFormat = "json"
+)
+
+// OCSMeta is the meta block of the responses of the OCS API,
+// reporting the outcome of the request.
+type OCSMeta struct {
+	Status string `xml:"status" json:"status"`
+
+	// StatusCode is 100 on success for the v1 API, 200 for the v2
+	// API.
+	StatusCode uint   `xml:"statuscode" json:"statuscode"`
+	Message    string `xml:"message" json:"message"`
+}
+
+// err returns the error reported by m.
+func (m *OCSMeta) err() error {
+	return &OCSError{StatusCode: m.StatusCode, Message: m.Message}
+}
+
+// ocsResponse is the envelope of the responses of the OCS API.
+type ocsResponse struct {
+	XMLName xml.Name `xml:"ocs"`
+	Meta    OCSMeta  `xml:"meta"`
+	Data    struct {
+		Inner []byte `xml:",innerxml"`
+	} `xml:"data"`
+}
+
+// ocsJSONResponse is the envelope of the JSON responses of the OCS
+// API.
+type ocsJSONResponse struct {
+	Ocs struct {
+		Meta OCSMeta         `json:"meta"`
+		Data json.RawMessage `json:"data"`
+	} `json:"ocs"`
+}
+
+// ocsUint is an unsigned integer of the OCS API, which some
+// endpoints send as a string in JSON, e.g. the id of a share.
+type ocsUint uint
+
+func (n *ocsUint) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" {
+		return nil
+	}
+	v, err := strconv.ParseUint(s, 10, 0)
+	if err != nil {
+		return err
+	}
+	*n = ocsUint(v)
+	return nil
+}
+
+// OCSError is returned when the OCS API reports a failure.
+type OCSError struct {
+	// StatusCode is the status code of the OCS response, which
+	// is not necessarily an HTTP status code.
+	StatusCode uint
+
+	// Message is the error message from the server, if any.
+	Message string
+}
+
+func (e *OCSError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("OCS API returned an unsuccessful status code %d", e.StatusCode)
+	}
+	return fmt.Sprintf("OCS API returned an unsuccessful status code %d: %s", e.StatusCode, e.Message)
+}
+
+// sendOCS sends a request to the given endpoint of the OCS v2 API,
+// e.g. "cloud/users", and unmarshals the data element of the response
+// into v, if v is not nil.
+func (c *Client) sendOCS(method string, endpoint string, data url.Values, v interface{}) error {
+	body, meta, err := c.sendOCSRequest(context.Background(), method, path.Join("ocs/v2.php", endpoint), data.Encode())
+	if err != nil {
+		return err
+	}
+	if meta.StatusCode != 200 {
+		return meta.err()
+	}
+
+	if v == nil {
+		return nil
+	}
+	return c.unmarshalOCS(body, v)
+}
+
+// unmarshalOCS parses data, the data element of a response of the
+// OCS API, into v according to c.OCSFormat. As in XML, where the
+// items of a list are element tags, a JSON list is seen as an object
+// holding the items in its "element" field.
+func (c *Client) unmarshalOCS(data []byte, v interface{}) error {
+	if c.OCSFormat != OCSFormatJSON {
+		return xml.Unmarshal(data, v)
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		data = append(append([]byte(`{"element":`), data...), '}')
+	}
+	return json.Unmarshal(data, v)
+}
+
+// sendOCSRequest sends a request to the given path of the OCS API,
+// relative to the server URL, with a form-encoded body. It returns
+// the data element of the response, in the format of c.OCSFormat,
+// and its meta block, whose status code is left to the caller to
+// check, since it depends on the version of the API. A 204 No
+// Content or 304 Not Modified response, which has no body, yields no
+// data and a meta block with its HTTP status code.
+func (c *Client) sendOCSRequest(ctx context.Context, method string, path string, body string) ([]byte, *OCSMeta, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.OCSFormat == OCSFormatJSON {
+		query := u.Query()
+		query.Set("format", "json")
+		u.RawQuery = query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.Url.ResolveReference(u).String(), strings.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req.Header.Add("OCS-APIRequest", "true")
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	c.authenticate(req)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, nil, contextErr(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotModified {
+		return nil, &OCSMeta{StatusCode: uint(resp.StatusCode)}, nil
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, contextErr(ctx, err)
+	}
+
+	if c.OCSFormat == OCSFormatJSON {
+		result := ocsJSONResponse{}
+		err = json.Unmarshal(respBody, &result)
+		if err != nil {
+			return nil, nil, err
+		}
+		return result.Ocs.Data, &result.Ocs.Meta, nil
+	}
+
+	result := ocsResponse{}
+	err = xml.Unmarshal(respBody, &result)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := append([]byte("<data>"), result.Data.Inner...)
+	return append(data, "</data>"...), &result.Meta, nil
+}
@@ -0,0 +1,76 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+func (t *testSuite) TestSendOCSRequest() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Equal("/ocs/v1.php/cloud/users", r.URL.Path)
+		t.Equal("search=bob", r.URL.RawQuery)
+		fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>100</statuscode><message>OK</message></meta><data><users><element>bob</element></users></data></ocs>`)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	data, meta, err := c.sendOCSRequest(context.Background(), "GET", "ocs/v1.php/cloud/users?search=bob", "")
+	t.Nil(err)
+	t.Equal(&OCSMeta{Status: "ok", StatusCode: 100, Message: "OK"}, meta)
+	t.Equal("<data><users><element>bob</element></users></data>", string(data))
+}
+
+func (t *testSuite) TestOCSFormatJSON() {
+	responses := map[string]string{
+		"/ocs/v2.php/apps/files_sharing/api/v1/shares": `[{"id":"12","share_type":3,"uid_owner":"admin","permissions":1,"path":"\/Test","share_with":null,"token":"abc","expiration":null,"attributes":"[{\"scope\":\"permissions\",\"key\":\"download\",\"value\":false}]"}]`,
+		"/apps/groupfolders/folders":                   `{"id":5,"mount_point":"GroupFolder"}`,
+		"/ocs/v2.php/cloud/capabilities":               `{"version":{"major":25},"capabilities":{"files_sharing":{"api_enabled":true,"public":{"enabled":false}}}}`,
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Equal("json", r.URL.Query().Get("format"))
+		statusCode := 200
+		if strings.HasPrefix(r.URL.Path, "/apps/") {
+			statusCode = 100
+		}
+		data, ok := responses[r.URL.Path]
+		if !ok {
+			statusCode, data = 404, "[]"
+		}
+		fmt.Fprintf(w, `{"ocs":{"meta":{"status":"ok","statuscode":%d,"message":"OK & done"},"data":%s}}`, statusCode, data)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+	c.OCSFormat = OCSFormatJSON
+	c.flavor = FlavorNextcloud
+
+	shares, err := c.ListShares()
+	t.Nil(err)
+	t.Equal([]Share{{
+		Id:          12,
+		ShareType:   ShareTypePublic,
+		UidOwner:    "admin",
+		Permissions: 1,
+		Path:        "/Test",
+		Token:       "abc",
+		Attributes:  ShareAttributes{{Scope: "permissions", Key: "download", Value: false}},
+	}}, shares)
+
+	result, err := c.CreateGroupFolder("GroupFolder")
+	t.Nil(err)
+	t.Equal(uint(5), result.Id)
+
+	capabilities, err := c.Capabilities()
+	t.Nil(err)
+	t.True(capabilities.Enabled("files_sharing", "api_enabled"))
+	t.False(capabilities.Enabled("files_sharing", "public", "enabled"))
+
+	_, err = c.DeleteShare(13)
+	t.Equal(&OCSError{StatusCode: 404, Message: "OK & done"}, err)
+}
@@ -0,0 +1,51 @@
+package cloud
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Permission is a bitmask of the permissions granted on a share or
+// a group folder.
+type Permission int
+
+// Permissions, to be combined with | or CombinePermissions.
+const (
+	PermissionRead   Permission = 1  // read and download
+	PermissionUpdate Permission = 2  // modify files
+	PermissionCreate Permission = 4  // upload new files, create folders
+	PermissionDelete Permission = 8  // delete files and folders
+	PermissionShare  Permission = 16 // share further
+	PermissionAll    Permission = 31 // all of the above
+)
+
+// CombinePermissions returns the union of the given permissions.
+func CombinePermissions(permissions ...Permission) Permission {
+	var p Permission
+	for _, permission := range permissions {
+		p |= permission
+	}
+	return p
+}
+
+// rolePermissions maps the share roles of the Nextcloud web
+// interface to the permissions they grant.
+var rolePermissions = map[string]Permission{
+	"viewer":    PermissionRead,
+	"editor":    PermissionRead | PermissionUpdate | PermissionCreate | PermissionDelete,
+	"uploader":  PermissionCreate,
+	"file-drop": PermissionCreate,
+	"manager":   PermissionAll,
+}
+
+// PermissionForRole returns the permissions granted by the given
+// share role: "viewer" (read only), "editor" (read, update, create
+// and delete), "uploader" or "file-drop" (create only) and "manager"
+// (all). Role names are case insensitive.
+func PermissionForRole(role string) (Permission, error) {
+	permission, ok := rolePermissions[strings.ToLower(role)]
+	if !ok {
+		return 0, fmt.Errorf("unknown share role %q", role)
+	}
+	return permission, nil
+}
@@ -0,0 +1,44 @@
+package cloud
+
+func (t *testSuite) TestPermissionForRole() {
+	permission, err := PermissionForRole("Viewer")
+	t.Nil(err)
+	t.Equal(PermissionRead, permission)
+
+	permission, err = PermissionForRole("editor")
+	t.Nil(err)
+	t.Equal(Permission(15), permission)
+
+	permission, err = PermissionForRole("file-drop")
+	t.Nil(err)
+	t.Equal(PermissionCreate, permission)
+
+	_, err = PermissionForRole("owner")
+	t.NotNil(err)
+}
+
+func (t *testSuite) TestCombinePermissions() {
+	t.Equal(Permission(0), CombinePermissions())
+	t.Equal(Permission(3), CombinePermissions(PermissionRead, PermissionUpdate))
+	t.Equal(PermissionAll, CombinePermissions(PermissionRead, PermissionUpdate, PermissionCreate, PermissionDelete, PermissionShare))
+}
+
+func (t *testSuite) TestShareRole() {
+	s := newShareServer()
+	defer s.Close()
+
+	c, err := Dial(s.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	share, err := c.CreateShare("ShareTest", ShareOptions{ShareType: ShareTypeUser, ShareWith: "bob", Role: "Editor"})
+	t.Nil(err)
+	if share != nil {
+		t.Equal(15, share.Permissions)
+	}
+
+	_, err = c.CreateShare("ShareTest", ShareOptions{ShareType: ShareTypeUser, ShareWith: "bob", Role: "owner"})
+	t.NotNil(err)
+	_, err = c.CreateShare("ShareTest", ShareOptions{ShareType: ShareTypeUser, ShareWith: "bob", Role: "viewer", Permissions: PermissionAll})
+	t.NotNil(err)
+	t.Equal(1, len(s.requests))
+}
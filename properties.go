@@ -0,0 +1,80 @@
+package cloud
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// SetProperty sets the property name of the given namespace on the
+// file at path, e.g. SetProperty(p, "http://owncloud.org/ns",
+// "favorite", "1"). name must be an XML name without a prefix.
+// Custom properties are stored by the server as is.
+func (c *Client) SetProperty(path, namespace, name, value string) error {
+	var buf bytes.Buffer
+	if err := xml.EscapeText(&buf, []byte(value)); err != nil {
+		return err
+	}
+	return c.proppatch(path, "set", namespace, name, buf.String())
+}
+
+// RemoveProperty removes the property name of the given namespace
+// from the file at path.
+func (c *Client) RemoveProperty(path, namespace, name string) error {
+	return c.proppatch(path, "remove", namespace, name, "")
+}
+
+// proppatch sends a PROPPATCH request applying the given
+// instruction, "set" or "remove", to a property of path. value must
+// be escaped.
+func (c *Client) proppatch(path, instruction, namespace, name, value string) error {
+	// name is written as is into the body.
+	if !isNCName(name) {
+		return fmt.Errorf("invalid property name %q", name)
+	}
+	var ns bytes.Buffer
+	if err := xml.EscapeText(&ns, []byte(namespace)); err != nil {
+		return err
+	}
+	body := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<d:propertyupdate xmlns:d="DAV:" xmlns:x="%s"><d:%s><d:prop><x:%s>%s</x:%s></d:prop></d:%s></d:propertyupdate>`,
+		ns.String(), instruction, name, value, name, instruction)
+
+	req, err := c.newWebDavRequest("PROPPATCH", path, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	result, err := c.sendMultistatusRequest(req)
+	if err != nil {
+		return err
+	}
+	for _, response := range result.Responses {
+		for _, propstat := range response.Propstats {
+			if !strings.Contains(propstat.Status, " 200 ") {
+				return fmt.Errorf("PROPPATCH %s: %s of %s failed: %s", path, instruction, name, propstat.Status)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isNCName reports whether s is an XML name without a colon, as
+// required for the local name of a property.
+func isNCName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || unicode.IsMark(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package cloud
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+)
+
+func (t *testSuite) TestProperties() {
+	var body, status string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Equal("PROPPATCH", r.Method)
+		t.Equal("/remote.php/webdav/Test/test.txt", r.URL.Path)
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprintf(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:x="http://example.com/ns"><d:response><d:href>/remote.php/webdav/Test/test.txt</d:href><d:propstat><d:prop><x:color/></d:prop><d:status>HTTP/1.1 %s</d:status></d:propstat></d:response></d:multistatus>`, status)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	status = "200 OK"
+	t.Nil(c.SetProperty("Test/test.txt", "http://example.com/ns", "color", "<red & blue>"))
+	t.Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<d:propertyupdate xmlns:d="DAV:" xmlns:x="http://example.com/ns"><d:set><d:prop><x:color>&lt;red &amp; blue&gt;</x:color></d:prop></d:set></d:propertyupdate>`, body)
+
+	t.Nil(c.RemoveProperty("Test/test.txt", "http://example.com/ns", "color"))
+	t.Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<d:propertyupdate xmlns:d="DAV:" xmlns:x="http://example.com/ns"><d:remove><d:prop><x:color></x:color></d:prop></d:remove></d:propertyupdate>`, body)
+
+	t.Nil(c.SetProperty("Test/test.txt", `http://example.com/ns?a="b"&c`, "color", "red"))
+	t.Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<d:propertyupdate xmlns:d="DAV:" xmlns:x="http://example.com/ns?a=&#34;b&#34;&amp;c"><d:set><d:prop><x:color>red</x:color></d:prop></d:set></d:propertyupdate>`, body)
+
+	body = ""
+	for _, name := range []string{"", "x:color", "1color", "color>", "my color"} {
+		t.NotNil(c.SetProperty("Test/test.txt", "http://example.com/ns", name, "red"))
+		t.NotNil(c.RemoveProperty("Test/test.txt", "http://example.com/ns", name))
+	}
+	t.Equal("", body)
+	t.Nil(c.SetProperty("Test/test.txt", "http://example.com/ns", "_my-color.2", "red"))
+
+	status = "403 Forbidden"
+	t.NotNil(c.SetProperty("Test/test.txt", "http://example.com/ns", "color", "red"))
+}
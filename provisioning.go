@@ -0,0 +1,31 @@
+package cloud
+
+import (
+	"net/url"
+	"path"
+)
+
+// CreateUser creates the user userid with the given password. It
+// requires administrator rights.
+func (c *Client) CreateUser(userid, password string) error {
+	return c.sendOCS("POST", "cloud/users", url.Values{"userid": {userid}, "password": {password}}, nil)
+}
+
+// DeleteUser deletes the user userid along with their files. It
+// requires administrator rights.
+func (c *Client) DeleteUser(userid string) error {
+	return c.sendOCS("DELETE", path.Join("cloud/users", url.PathEscape(userid)), nil, nil)
+}
+
+// CreateGroup creates the group groupid. It requires administrator
+// rights.
+func (c *Client) CreateGroup(groupid string) error {
+	return c.sendOCS("POST", "cloud/groups", url.Values{"groupid": {groupid}}, nil)
+}
+
+// AddUserToGroup adds the user userid to the group groupid. It
+// requires administrator rights, or being an administrator of the
+// group.
+func (c *Client) AddUserToGroup(userid, groupid string) error {
+	return c.sendOCS("POST", path.Join("cloud/users", url.PathEscape(userid), "groups"), url.Values{"groupid": {groupid}}, nil)
+}
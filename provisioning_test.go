@@ -0,0 +1,38 @@
+package cloud
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+func (t *testSuite) TestProvisioning() {
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		requests = append(requests, r.Method+" "+r.URL.EscapedPath()+" "+r.PostForm.Encode())
+		statusCode := 200
+		if r.PostForm.Get("userid") == "taken" {
+			statusCode = 102
+		}
+		fmt.Fprintf(w, `<?xml version="1.0"?><ocs><meta><statuscode>%d</statuscode><message>User already exists</message></meta><data/></ocs>`, statusCode)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	t.Nil(c.CreateUser("bob", "s3cret&"))
+	t.Nil(c.CreateGroup("staff"))
+	t.Nil(c.AddUserToGroup("bob", "staff"))
+	t.Nil(c.DeleteUser("bob/1"))
+	t.Equal([]string{
+		"POST /ocs/v2.php/cloud/users password=s3cret%26&userid=bob",
+		"POST /ocs/v2.php/cloud/groups groupid=staff",
+		"POST /ocs/v2.php/cloud/users/bob/groups groupid=staff",
+		"DELETE /ocs/v2.php/cloud/users/bob%2F1 ",
+	}, requests)
+
+	err = c.CreateUser("taken", "password")
+	t.Equal(&OCSError{StatusCode: 102, Message: "User already exists"}, err)
+}
@@ -0,0 +1,131 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Quota is a storage quota expressed in bytes. The server reports a
+// few special conditions with negative values which are normalized
+// by ParseQuota to the constants below.
+type Quota int64
+
+const (
+	// QuotaNotComputed means that the server didn't compute the
+	// quota yet.
+	QuotaNotComputed Quota = -1
+
+	// QuotaUnknown means that the quota is not known, e.g. because
+	// the default quota applies.
+	QuotaUnknown Quota = -2
+
+	// QuotaUnlimited means that no limit is set.
+	QuotaUnlimited Quota = -3
+)
+
+var quotaUnits = map[string]int64{
+	"b":  1,
+	"k":  1 << 10,
+	"kb": 1 << 10,
+	"m":  1 << 20,
+	"mb": 1 << 20,
+	"g":  1 << 30,
+	"gb": 1 << 30,
+	"t":  1 << 40,
+	"tb": 1 << 40,
+	"p":  1 << 50,
+	"pb": 1 << 50,
+}
+
+// ParseQuota parses a quota as returned by the different endpoints
+// of the server. It accepts plain byte counts, the negative
+// sentinels, the "none" and "default" keywords and human readable
+// sizes like "5 GB".
+func ParseQuota(s string) (Quota, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "none", "unlimited":
+		return QuotaUnlimited, nil
+	case "", "default":
+		return QuotaUnknown, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n < int64(QuotaUnlimited) {
+			return 0, fmt.Errorf("invalid quota %q", s)
+		}
+		return Quota(n), nil
+	}
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i <= 0 {
+		return 0, fmt.Errorf("invalid quota %q", s)
+	}
+	unit, ok := quotaUnits[strings.TrimSpace(s[i:])]
+	if !ok {
+		return 0, fmt.Errorf("invalid quota unit in %q", s)
+	}
+	n, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quota %q", s)
+	}
+	return Quota(n * float64(unit)), nil
+}
+
+// IsUnlimited reports whether q means that no limit is set.
+func (q Quota) IsUnlimited() bool {
+	return q == QuotaUnlimited
+}
+
+// IsKnown reports whether q is an actual amount of bytes rather
+// than one of the sentinels.
+func (q Quota) IsKnown() bool {
+	return q >= 0
+}
+
+func (q Quota) String() string {
+	switch q {
+	case QuotaUnlimited:
+		return "none"
+	case QuotaUnknown:
+		return "default"
+	case QuotaNotComputed:
+		return "not computed"
+	}
+	return strconv.FormatInt(int64(q), 10)
+}
+
+// SetQuotaForGroupFolder sets the quota of the given group
+// folder. Use QuotaUnlimited to remove the limit.
+func (c *Client) SetQuotaForGroupFolder(quota Quota, folderId uint) (*ShareResult, error) {
+	return c.SetQuotaForGroupFolderContext(context.Background(), quota, folderId)
+}
+
+// SetQuotaForGroupFolderContext is like SetQuotaForGroupFolder but
+// aborts the request when ctx is done.
+func (c *Client) SetQuotaForGroupFolderContext(ctx context.Context, quota Quota, folderId uint) (*ShareResult, error) {
+	return c.sendGroupFoldersRequest(ctx, "POST", fmt.Sprintf("folders/%d/quota", folderId), fmt.Sprintf("quota=%d", quota))
+}
+
+// Quota returns the storage used by the user and the storage still
+// available, in bytes. available is one of the negative Quota
+// constants when the server can't tell: QuotaUnlimited (-3) means
+// that there is no limit, which available.IsUnlimited reports.
+func (c *Client) Quota() (used int64, available Quota, err error) {
+	result, err := c.propfind("", "0", "<d:quota-used-bytes/><d:quota-available-bytes/>")
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(result.Responses) == 0 {
+		return 0, 0, fmt.Errorf("no quota in PROPFIND response")
+	}
+
+	prop := result.Responses[0].prop()
+	quota, err := ParseQuota(prop.QuotaAvailable)
+	if err != nil {
+		return 0, 0, err
+	}
+	return prop.QuotaUsed, quota, nil
+}
@@ -0,0 +1,80 @@
+package cloud
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+func (t *testSuite) TestParseQuota() {
+	for s, expected := range map[string]Quota{
+		"1024":    1024,
+		"-3":      QuotaUnlimited,
+		"none":    QuotaUnlimited,
+		"-2":      QuotaUnknown,
+		"default": QuotaUnknown,
+		"5 GB":    5 << 30,
+		"1.5MB":   3 << 19,
+	} {
+		q, err := ParseQuota(s)
+		t.Nil(err)
+		t.Equal(expected, q)
+	}
+	q, _ := ParseQuota("none")
+	t.True(q.IsUnlimited())
+	t.False(q.IsKnown())
+
+	_, err := ParseQuota("5 XB")
+	t.NotNil(err)
+}
+
+func (t *testSuite) TestSetQuotaForGroupFolder() {
+	groupFolder, err := client.CreateGroupFolder("QuotaFolder")
+	t.Nil(err)
+	if groupFolder != nil {
+		result, err := client.SetQuotaForGroupFolder(QuotaUnlimited, groupFolder.Id)
+		t.Nil(err)
+		if result != nil {
+			t.Equal(uint(100), result.StatusCode)
+		}
+	}
+}
+
+func (t *testSuite) TestQuota() {
+	var available string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Equal("PROPFIND", r.Method)
+		t.Equal("0", r.Header.Get("Depth"))
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprintf(w, `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:s="http://sabredav.org/ns" xmlns:oc="http://owncloud.org/ns" xmlns:nc="http://nextcloud.org/ns">
+ <d:response>
+  <d:href>/remote.php/webdav/</d:href>
+  <d:propstat>
+   <d:prop>
+    <d:quota-used-bytes>1048576</d:quota-used-bytes>
+    <d:quota-available-bytes>%s</d:quota-available-bytes>
+   </d:prop>
+   <d:status>HTTP/1.1 200 OK</d:status>
+  </d:propstat>
+ </d:response>
+</d:multistatus>`, available)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	available = "5367660544"
+	used, free, err := c.Quota()
+	t.Nil(err)
+	t.Equal(int64(1048576), used)
+	t.Equal(Quota(5367660544), free)
+	t.True(free.IsKnown())
+
+	available = "-3"
+	_, free, err = c.Quota()
+	t.Nil(err)
+	t.Equal(QuotaUnlimited, free)
+	t.True(free.IsUnlimited())
+}
@@ -0,0 +1,59 @@
+package cloud
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrRangeIgnored is returned when the server, or a proxy, answers a
+// range request with the whole file.
+var ErrRangeIgnored = errors.New("range request not honored by the server")
+
+// DownloadRange downloads length bytes of the file at path, starting
+// at offset. Fewer bytes are returned if the file ends before.
+func (c *Client) DownloadRange(path string, offset, length int64) ([]byte, error) {
+	start := time.Now()
+	data, err := c.downloadRange(path, offset, length)
+	c.emit(OpDownload, path, int64(len(data)), start, err)
+	return data, err
+}
+
+// downloadRange downloads length bytes of the file at path, starting
+// at offset, see DownloadRange.
+func (c *Client) downloadRange(path string, offset, length int64) ([]byte, error) {
+	if offset < 0 || length <= 0 {
+		return nil, fmt.Errorf("invalid range %d+%d", offset, length)
+	}
+
+	req, err := c.newWebDavRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		return nil, ErrRangeIgnored
+	default:
+		return nil, newStatusError(resp)
+	}
+
+	// Content-Range is "bytes first-last/size".
+	contentRange := resp.Header.Get("Content-Range")
+	if !strings.HasPrefix(contentRange, fmt.Sprintf("bytes %d-", offset)) {
+		return nil, fmt.Errorf("unexpected content range %q for offset %d", contentRange, offset)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
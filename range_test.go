@@ -0,0 +1,34 @@
+package cloud
+
+import (
+	"net/http"
+	"net/http/httptest"
+)
+
+func (t *testSuite) TestDownloadRange() {
+	s := newDavServer()
+	defer s.Close()
+	s.files["/test.txt"] = []byte("Hello World!\n")
+	c := s.client()
+
+	data, err := c.DownloadRange("test.txt", 6, 5)
+	t.Nil(err)
+	t.Equal("World", string(data))
+
+	data, err = c.DownloadRange("test.txt", 6, 100)
+	t.Nil(err)
+	t.Equal("World!\n", string(data))
+
+	_, err = c.DownloadRange("test.txt", 0, 0)
+	t.NotNil(err)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Hello World!\n"))
+	}))
+	defer ts.Close()
+	c, err = Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	_, err = c.DownloadRange("test.txt", 6, 5)
+	t.Equal(ErrRangeIgnored, err)
+}
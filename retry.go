@@ -0,0 +1,120 @@
+package cloud
+
+import (
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures how requests failing with a transient
+// error are retried. Requests without side effects, like downloads
+// and listings, are retried on connection errors and on the 429, 502
+// and 503 status codes, which load balancers return during
+// maintenance. Other requests, like uploads, are retried only if the
+// connection failed before the request was sent.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, the first
+	// one included.
+	MaxAttempts int
+
+	// Backoff is the wait before the first retry, doubled at each
+	// further attempt. A Retry-After header sent by the server
+	// takes precedence. Zero means one second.
+	Backoff time.Duration
+}
+
+// idempotentMethods are the methods retried on transient status
+// codes.
+var idempotentMethods = map[string]bool{
+	"GET":      true,
+	"HEAD":     true,
+	"OPTIONS":  true,
+	"PROPFIND": true,
+	"MKCOL":    true,
+	"DELETE":   true,
+}
+
+// transientStatus are the status codes of the failures worth a
+// retry.
+var transientStatus = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+}
+
+// do sends req, retrying it as configured by c.RetryPolicy.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	policy := c.RetryPolicy
+	// A request whose body can't be read again is sent once.
+	if policy == nil || policy.MaxAttempts <= 1 || (req.Body != nil && req.GetBody == nil) {
+		return c.send(req)
+	}
+
+	backoff := policy.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	idempotent := idempotentMethods[req.Method]
+	for attempt := 1; ; attempt++ {
+		var sent bool
+		trace := &httptrace.ClientTrace{
+			WroteHeaders: func() { sent = true },
+		}
+		attemptReq := req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+
+		resp, err := c.send(attemptReq)
+		if attempt >= policy.MaxAttempts {
+			return resp, err
+		}
+
+		wait := backoff
+		switch {
+		case err != nil:
+			if req.Context().Err() != nil || (sent && !idempotent) {
+				return resp, err
+			}
+		case idempotent && transientStatus[resp.StatusCode]:
+			if d, ok := retryAfter(resp); ok {
+				wait = d
+			}
+			resp.Body.Close()
+		default:
+			return resp, nil
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		backoff *= 2
+	}
+}
+
+// retryAfter returns the delay requested by the Retry-After header of
+// resp, if any.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		d := time.Until(date)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
@@ -0,0 +1,56 @@
+package cloud
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+func (t *testSuite) TestRetryPolicy() {
+	var failures, attempts int
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if failures > 0 {
+			failures--
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("Hello"))
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+	c.RetryPolicy = &RetryPolicy{MaxAttempts: 3, Backoff: time.Hour}
+
+	failures = 2
+	data, err := c.Download("test.txt")
+	t.Nil(err)
+	t.Equal("Hello", string(data))
+	t.Equal(3, attempts)
+
+	attempts, failures = 0, 3
+	_, err = c.Download("test.txt")
+	t.NotNil(err)
+	t.Equal(3, attempts)
+
+	// Uploads are not retried once sent.
+	attempts, failures = 0, 1
+	t.NotNil(c.Upload([]byte("data"), "test.txt"))
+	t.Equal(1, attempts)
+
+	// The body of a PROPFIND is sent again.
+	attempts, failures, bodies = 0, 1, nil
+	c.propfind("test.txt", "0", "<d:getetag/>")
+	t.Equal(2, attempts)
+	if len(bodies) == 2 {
+		t.True(strings.Contains(bodies[0], "<d:getetag/>"))
+		t.Equal(bodies[0], bodies[1])
+	}
+}
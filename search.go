@@ -0,0 +1,88 @@
+package cloud
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"path"
+	"strings"
+)
+
+// SearchQuery selects the files returned by Search. Only the set
+// fields are used, and a file must match all of them. At least one
+// field must be set.
+type SearchQuery struct {
+	// Name is a pattern matched against the name of the files, in
+	// which "%" matches any sequence of characters, e.g. "%.pdf".
+	// The match is case-insensitive.
+	Name string
+
+	// ContentType is a pattern matched against the MIME type of the
+	// files, e.g. "image/%".
+	ContentType string
+
+	// Favorite selects the favorite files only.
+	Favorite bool
+}
+
+// Search returns the files of the user matching query. The search
+// is performed by the server, which is much faster than listing a
+// large library. It uses a WebDAV SEARCH request, supported by
+// Nextcloud 15 and later: the filter-files REPORT of older servers
+// can't filter by name or content type.
+func (c *Client) Search(query SearchQuery) ([]FileInfo, error) {
+	var where []string
+	if query.Name != "" {
+		where = append(where, searchCondition("like", "<d:displayname/>", query.Name))
+	}
+	if query.ContentType != "" {
+		where = append(where, searchCondition("like", "<d:getcontenttype/>", query.ContentType))
+	}
+	if query.Favorite {
+		where = append(where, searchCondition("eq", "<oc:favorite/>", "1"))
+	}
+	if len(where) == 0 {
+		return nil, errors.New("empty search query")
+	}
+	condition := strings.Join(where, "")
+	if len(where) > 1 {
+		condition = "<d:and>" + condition + "</d:and>"
+	}
+
+	var scope bytes.Buffer
+	xml.EscapeText(&scope, []byte(path.Join("/files", c.Username)))
+
+	body := `<?xml version="1.0" encoding="UTF-8"?>
+<d:searchrequest xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns" xmlns:nc="http://nextcloud.org/ns"><d:basicsearch>
+<d:select><d:prop>` + fileInfoProps + `</d:prop></d:select>
+<d:from><d:scope><d:href>` + scope.String() + `</d:href><d:depth>infinity</d:depth></d:scope></d:from>
+<d:where>` + condition + `</d:where>
+</d:basicsearch></d:searchrequest>`
+	req, err := c.newRequest("SEARCH", "remote.php/dav/", strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.sendMultistatusRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]FileInfo, 0, len(result.Responses))
+	for i := range result.Responses {
+		info, err := c.fileInfo(&result.Responses[i], c.filesRoot())
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, info)
+	}
+	return files, nil
+}
+
+// searchCondition returns a condition of a basic search comparing the
+// given property with the literal value.
+func searchCondition(operator, prop, value string) string {
+	var literal bytes.Buffer
+	xml.EscapeText(&literal, []byte(value))
+	return "<d:" + operator + "><d:prop>" + prop + "</d:prop><d:literal>" + literal.String() + "</d:literal></d:" + operator + ">"
+}
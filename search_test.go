@@ -0,0 +1,52 @@
+package cloud
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+func (t *testSuite) TestSearch() {
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		t.Equal("SEARCH", r.Method)
+		t.Equal("/remote.php/dav/", r.URL.Path)
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprint(w, `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:">
+ <d:response>
+  <d:href>/remote.php/dav/files/admin/Docs/report.pdf</d:href>
+  <d:propstat><d:prop><d:resourcetype/><d:getcontentlength>42</d:getcontentlength><d:getcontenttype>application/pdf</d:getcontenttype></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+</d:multistatus>`)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	files, err := c.Search(SearchQuery{Name: "%.pdf", ContentType: "application/pdf", Favorite: true})
+	t.Nil(err)
+	t.Equal([]FileInfo{{
+		Name:        "report.pdf",
+		Path:        "/Docs/report.pdf",
+		Href:        "/remote.php/dav/files/admin/Docs/report.pdf",
+		Size:        42,
+		ContentType: "application/pdf",
+	}}, files)
+	t.True(strings.Contains(body, "<d:href>/files/admin</d:href>"))
+	t.True(strings.Contains(body, "<d:and><d:like><d:prop><d:displayname/></d:prop><d:literal>%.pdf</d:literal></d:like>"))
+	t.True(strings.Contains(body, "<d:like><d:prop><d:getcontenttype/></d:prop><d:literal>application/pdf</d:literal></d:like>"))
+	t.True(strings.Contains(body, "<d:eq><d:prop><oc:favorite/></d:prop><d:literal>1</d:literal></d:eq></d:and>"))
+
+	_, err = c.Search(SearchQuery{Name: "a&b"})
+	t.Nil(err)
+	t.True(strings.Contains(body, "<d:where><d:like><d:prop><d:displayname/></d:prop><d:literal>a&amp;b</d:literal></d:like></d:where>"))
+
+	_, err = c.Search(SearchQuery{})
+	t.NotNil(err)
+}
@@ -0,0 +1,588 @@
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+)
+
+// Share types accepted and returned by the share API.
+const (
+	ShareTypeUser      = 0
+	ShareTypeGroup     = 1
+	ShareTypePublic    = 3
+	ShareTypeEmail     = 4
+	ShareTypeFederated = 6
+	ShareTypeDeck      = 12
+)
+
+// Sharee is a user or group a file is shared with.
+type Sharee struct {
+	Id          string
+	ShareType   int
+	DisplayName string
+	Permissions int
+}
+
+// SharedWith returns the users and groups the file at path is shared
+// with, including reshares, with their display names resolved.
+// Public links are not included since they have no recipient.
+func (c *Client) SharedWith(path string) ([]Sharee, error) {
+	shares, err := c.GetSharesForPath(path, true, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var sharees []Sharee
+	for _, share := range shares {
+		if share.ShareType == ShareTypePublic {
+			continue
+		}
+		sharee := Sharee{
+			Id:          share.ShareWith,
+			ShareType:   share.ShareType,
+			DisplayName: share.ShareWithDisplayname,
+			Permissions: share.Permissions,
+		}
+		if sharee.DisplayName == "" || sharee.DisplayName == sharee.Id {
+			sharee.DisplayName = c.displayName(share.ShareType, share.ShareWith)
+		}
+		sharees = append(sharees, sharee)
+	}
+
+	return sharees, nil
+}
+
+// displayName resolves the display name of the given user or group
+// through the provisioning API. It falls back to id when the name
+// can't be resolved, e.g. for lack of permissions.
+func (c *Client) displayName(shareType int, id string) string {
+	switch shareType {
+	case ShareTypeUser:
+		user := struct {
+			DisplayName string `xml:"displayname" json:"displayname"`
+		}{}
+		err := c.sendOCS("GET", path.Join("cloud/users", url.PathEscape(id)), nil, &user)
+		if err == nil && user.DisplayName != "" {
+			return user.DisplayName
+		}
+	case ShareTypeGroup:
+		groups := struct {
+			Groups []struct {
+				Id          string `xml:"id" json:"id"`
+				DisplayName string `xml:"displayname" json:"displayname"`
+			} `xml:"groups>element" json:"groups"`
+		}{}
+		err := c.sendOCS("GET", "cloud/groups/details?search="+url.QueryEscape(id), nil, &groups)
+		if err == nil {
+			for _, group := range groups.Groups {
+				if group.Id == id && group.DisplayName != "" {
+					return group.DisplayName
+				}
+			}
+		}
+	}
+	return id
+}
+
+// ShareAttribute is an advanced share setting, e.g. the
+// "permissions" scoped "download" key which tells whether the
+// recipient may download the shared files.
+type ShareAttribute struct {
+	Scope string      `json:"scope"`
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+// ShareAttributes are the advanced settings of a share. The server
+// transmits them as a JSON document.
+type ShareAttributes []ShareAttribute
+
+func (a *ShareAttributes) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := d.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	if s == "" || s == "null" {
+		*a = nil
+		return nil
+	}
+	return json.Unmarshal([]byte(s), (*[]ShareAttribute)(a))
+}
+
+// UnmarshalJSON decodes the attributes of a JSON response, which are
+// a JSON document in a string.
+func (a *ShareAttributes) UnmarshalJSON(data []byte) error {
+	var s *string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == nil || *s == "" || *s == "null" {
+		*a = nil
+		return nil
+	}
+	return json.Unmarshal([]byte(*s), (*[]ShareAttribute)(a))
+}
+
+// SetShareAttributes replaces the advanced settings of the given
+// share.
+func (c *Client) SetShareAttributes(shareId uint, attrs []ShareAttribute) error {
+	if attrs == nil {
+		attrs = []ShareAttribute{}
+	}
+	attributes, err := json.Marshal(attrs)
+	if err != nil {
+		return err
+	}
+	data := url.Values{}
+	data.Set("attributes", string(attributes))
+	_, err = c.sendSharesRequest(context.Background(), "PUT", fmt.Sprintf("shares/%d", shareId), data.Encode())
+	return err
+}
+
+// ListShares returns all the shares created by the user. The Remote
+// field of federated shares holds the server of their recipient.
+func (c *Client) ListShares() ([]ShareElement, error) {
+	result, err := c.sendSharesRequest(context.Background(), "GET", "shares", "")
+	if err != nil {
+		return nil, err
+	}
+	for i := range result.Elements {
+		setRemote(&result.Elements[i])
+	}
+	return result.Elements, nil
+}
+
+// SetResharingAllowed allows or forbids the recipient of the given
+// share to share it further. Resharing is controlled by the share
+// bit (16) of the permissions bitmask: this method toggles it,
+// leaving the other permissions untouched. To create a share which
+// can't be reshared, just leave that bit out of the permissions
+// passed on creation.
+func (c *Client) SetResharingAllowed(shareId uint, allowed bool) error {
+	share, err := c.GetShareByID(shareId)
+	if err != nil {
+		return err
+	}
+
+	permissions := Permission(share.Permissions) &^ PermissionShare
+	if allowed {
+		permissions |= PermissionShare
+	}
+	if permissions == Permission(share.Permissions) {
+		return nil
+	}
+
+	return c.UpdateSharePermissions(shareId, permissions)
+}
+
+// UpdateSharePermissions replaces the permissions of the given share.
+func (c *Client) UpdateSharePermissions(shareId uint, permissions Permission) error {
+	_, err := c.sendSharesRequest(context.Background(), "PUT", fmt.Sprintf("shares/%d", shareId), fmt.Sprintf("permissions=%d", permissions))
+	return err
+}
+
+// PasswordRejectedError is returned when the server refuses the
+// password of a share, usually because it doesn't comply with the
+// password policy.
+type PasswordRejectedError struct {
+	// Message is the reason given by the server, e.g. "Password
+	// needs to be at least 10 characters long".
+	Message string
+}
+
+func (e *PasswordRejectedError) Error() string {
+	return "share password rejected: " + e.Message
+}
+
+// passwordError maps the refusal of a share password, reported by
+// the server with the 403 OCS status code, to a
+// *PasswordRejectedError.
+func passwordError(err error) error {
+	if ocsErr, ok := err.(*OCSError); ok && ocsErr.StatusCode == http.StatusForbidden {
+		return &PasswordRejectedError{Message: ocsErr.Message}
+	}
+	return err
+}
+
+// CreatePasswordProtectedShare creates a public link on path with
+// the given permissions, protected by password. If the server refuses
+// the password, a *PasswordRejectedError is returned.
+func (c *Client) CreatePasswordProtectedShare(path, password string, permissions Permission) (*ShareResult, error) {
+	return c.createShare(context.Background(), path, ShareOptions{
+		ShareType:   ShareTypePublic,
+		Permissions: permissions,
+		Password:    password,
+	})
+}
+
+// SetSharePassword sets the password protecting the given public
+// link. An empty password removes the protection. If the server
+// refuses the password, a *PasswordRejectedError is returned.
+func (c *Client) SetSharePassword(shareId uint, password string) error {
+	data := url.Values{}
+	data.Set("password", password)
+	_, err := c.sendSharesRequest(context.Background(), "PUT", fmt.Sprintf("shares/%d", shareId), data.Encode())
+	return passwordError(err)
+}
+
+// ErrExpirationInPast is returned when setting a share expiration
+// date which is already past.
+var ErrExpirationInPast = errors.New("share expiration date is in the past")
+
+// expireDate formats expiry as expected by the share API, checking
+// that it is not in the past. Only the date of expiry matters.
+func expireDate(expiry time.Time) (string, error) {
+	today := time.Now().In(expiry.Location()).Format("2006-01-02")
+	date := expiry.Format("2006-01-02")
+	if date < today {
+		return "", ErrExpirationInPast
+	}
+	return date, nil
+}
+
+// CreateExpiringShare creates a public link on path with the given
+// permissions, expiring at the end of the day of expiry.
+func (c *Client) CreateExpiringShare(path string, permissions Permission, expiry time.Time) (*ShareResult, error) {
+	// A zero expiry would leave the expiration out.
+	if expiry.IsZero() {
+		return nil, ErrExpirationInPast
+	}
+	return c.createShare(context.Background(), path, ShareOptions{
+		ShareType:   ShareTypePublic,
+		Permissions: permissions,
+		ExpireDate:  expiry,
+	})
+}
+
+// SetShareExpiration makes the given share expire at the end of the
+// day of expiry.
+func (c *Client) SetShareExpiration(shareId uint, expiry time.Time) error {
+	date, err := expireDate(expiry)
+	if err != nil {
+		return err
+	}
+	data := url.Values{}
+	data.Set("expireDate", date)
+	_, err = c.sendSharesRequest(context.Background(), "PUT", fmt.Sprintf("shares/%d", shareId), data.Encode())
+	return err
+}
+
+// ErrShareNotFound is returned by GetShareByID when the share doesn't
+// exist, e.g. because it was deleted.
+var ErrShareNotFound = errors.New("share not found")
+
+// GetShareByID returns the current state of the share whose id is
+// given, e.g. to check the outcome of UpdateSharePermissions or
+// SetShareExpiration.
+func (c *Client) GetShareByID(shareId uint) (*Share, error) {
+	result, err := c.sendSharesRequest(context.Background(), "GET", fmt.Sprintf("shares/%d", shareId), "")
+	if ocsErr, ok := err.(*OCSError); ok && ocsErr.StatusCode == http.StatusNotFound {
+		return nil, ErrShareNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result.share()
+}
+
+// ShareWithUser shares path with the given user, granting the given
+// permissions.
+func (c *Client) ShareWithUser(path, username string, permissions Permission) (*ShareResult, error) {
+	return c.shareWith(path, ShareTypeUser, username, permissions)
+}
+
+// ShareWithGroup shares path with the members of the given group,
+// granting the given permissions.
+func (c *Client) ShareWithGroup(path, groupname string, permissions Permission) (*ShareResult, error) {
+	return c.shareWith(path, ShareTypeGroup, groupname, permissions)
+}
+
+func (c *Client) shareWith(path string, shareType int, shareWith string, permissions Permission) (*ShareResult, error) {
+	return c.createShare(context.Background(), path, ShareOptions{
+		ShareType:   shareType,
+		ShareWith:   shareWith,
+		Permissions: permissions,
+	})
+}
+
+// existingShare returns the share of path matching the given type,
+// recipient and permissions as a share creation result, or nil if
+// there is none.
+func (c *Client) existingShare(ctx context.Context, path string, shareType int, shareWith string, permissions int) (*ShareResult, error) {
+	shares, err := c.GetSharesForPathContext(ctx, path, false, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, share := range shares {
+		if share.ShareType == shareType && share.ShareWith == shareWith && share.Permissions == permissions {
+			return &ShareResult{
+				Status:     "ok",
+				StatusCode: 200,
+				Id:         share.Id,
+				Url:        share.Url,
+				Elements:   []Share{share},
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// ShareOptions are the settings of a new share. Apart from
+// ShareType, their zero values leave the respective setting out, to
+// the default of the server.
+type ShareOptions struct {
+	// ShareType is the kind of share, e.g. ShareTypePublic.
+	ShareType int
+
+	// ShareWith is the recipient of the share: a user, a group, an
+	// email address or a federated cloud id depending on
+	// ShareType. It is empty for public links.
+	ShareWith string
+
+	Permissions Permission
+
+	// Role sets the permissions by the name of a share role of
+	// the web interface, e.g. "editor", see PermissionForRole. It
+	// can't be combined with Permissions.
+	Role string
+
+	// Password protects a public link or a share by email.
+	Password string
+
+	// ExpireDate makes the share expire at the end of its day.
+	ExpireDate time.Time
+
+	// Note is shown to the recipients of the share, e.g. "please
+	// review by Friday".
+	Note string
+
+	// PublicUpload allows uploads to a public link to a folder.
+	// The server then resets the permissions to 15, so Permissions,
+	// if set, are restored by a second request.
+	PublicUpload bool
+
+	// HideDownload hides the download button of a public link. The
+	// server only accepts it when updating a share, so it costs a
+	// second request.
+	HideDownload bool
+}
+
+// CreateShare shares path with the settings of opts and returns the
+// new share. If the server refuses the password, a
+// *PasswordRejectedError is returned.
+func (c *Client) CreateShare(path string, opts ShareOptions) (*Share, error) {
+	return c.CreateShareContext(context.Background(), path, opts)
+}
+
+// CreateShareContext is like CreateShare but aborts the requests when
+// ctx is done.
+func (c *Client) CreateShareContext(ctx context.Context, path string, opts ShareOptions) (*Share, error) {
+	result, err := c.createShare(ctx, path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return result.share()
+}
+
+// createShare shares path with the settings of opts. If
+// c.DeduplicateShares is set and opts has no password, expiration,
+// note or hidden download, an existing share of the same type,
+// recipient and permissions is returned instead: those settings
+// can't be compared with the ones of the existing shares.
+func (c *Client) createShare(ctx context.Context, path string, opts ShareOptions) (*ShareResult, error) {
+	if opts.Role != "" {
+		if opts.Permissions != 0 {
+			return nil, errors.New("share role and permissions are mutually exclusive")
+		}
+		permissions, err := PermissionForRole(opts.Role)
+		if err != nil {
+			return nil, err
+		}
+		opts.Permissions = permissions
+	}
+
+	protected := opts.Password != "" || !opts.ExpireDate.IsZero() || opts.Note != "" || opts.HideDownload
+	if c.DeduplicateShares && !protected {
+		result, err := c.existingShare(ctx, path, opts.ShareType, opts.ShareWith, int(opts.Permissions))
+		if err != nil || result != nil {
+			return result, err
+		}
+	}
+
+	data := url.Values{}
+	data.Set("path", path)
+	data.Set("shareType", strconv.Itoa(opts.ShareType))
+	if opts.ShareWith != "" {
+		data.Set("shareWith", opts.ShareWith)
+	}
+	if opts.Permissions != 0 {
+		data.Set("permissions", strconv.Itoa(int(opts.Permissions)))
+	}
+	if opts.Password != "" {
+		data.Set("password", opts.Password)
+	}
+	if !opts.ExpireDate.IsZero() {
+		date, err := expireDate(opts.ExpireDate)
+		if err != nil {
+			return nil, err
+		}
+		data.Set("expireDate", date)
+	}
+	if opts.Note != "" {
+		data.Set("note", opts.Note)
+	}
+	if opts.PublicUpload {
+		data.Set("publicUpload", "true")
+	}
+
+	result, err := c.sendSharesRequest(ctx, "POST", "shares", data.Encode())
+	if err != nil && opts.Password != "" {
+		err = passwordError(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The server resets the permissions of a link with public
+	// upload to 15, and only accepts hiding the download when
+	// updating a share: both are fixed by a second request.
+	update := url.Values{}
+	if opts.PublicUpload && opts.Permissions != 0 {
+		update.Set("permissions", strconv.Itoa(int(opts.Permissions)))
+	}
+	if opts.HideDownload {
+		update.Set("hideDownload", "true")
+	}
+	if len(update) == 0 {
+		return result, nil
+	}
+	return c.sendSharesRequest(ctx, "PUT", fmt.Sprintf("shares/%d", result.Id), update.Encode())
+}
+
+// share returns the single share of r.
+func (r *ShareResult) share() (*Share, error) {
+	if len(r.Elements) != 1 {
+		return nil, fmt.Errorf("got %d shares, want 1", len(r.Elements))
+	}
+	return &r.Elements[0], nil
+}
+
+// SetShareNote sets the note shown to the recipients of the given
+// share. An empty note removes it.
+func (c *Client) SetShareNote(shareId uint, note string) error {
+	data := url.Values{}
+	data.Set("note", note)
+	_, err := c.sendSharesRequest(context.Background(), "PUT", fmt.Sprintf("shares/%d", shareId), data.Encode())
+	return err
+}
+
+// ShareByEmail shares path with an external recipient, who receives
+// an email with a link to it, granting the given permissions. It
+// requires the Share by mail app, and a mail server configured on
+// Nextcloud: otherwise the OCS error of the server is returned.
+func (c *Client) ShareByEmail(path, email string, permissions Permission) (*Share, error) {
+	return c.ShareByEmailProtected(path, email, permissions, "", "")
+}
+
+// ShareByEmailProtected is like ShareByEmail but protects the link
+// with password and sends note to the recipient along with it. An
+// empty password or note leave the respective setting out. If the
+// server refuses the password, a *PasswordRejectedError is returned.
+func (c *Client) ShareByEmailProtected(path, email string, permissions Permission, password, note string) (*Share, error) {
+	return c.CreateShare(path, ShareOptions{
+		ShareType:   ShareTypeEmail,
+		ShareWith:   email,
+		Permissions: permissions,
+		Password:    password,
+		Note:        note,
+	})
+}
+
+// CreateProtectedFileDropShare creates an upload only public link on
+// path, protected by password and expiring at expireDate. Both are
+// set by the request creating the link: it is never exposed without
+// its protection. An empty password or a zero expireDate leave the
+// respective setting out. If the server refuses the password, a
+// *PasswordRejectedError is returned.
+func (c *Client) CreateProtectedFileDropShare(path string, password string, expireDate time.Time) (*ShareResult, error) {
+	return c.createShare(context.Background(), path, ShareOptions{
+		ShareType:    ShareTypePublic,
+		Permissions:  PermissionCreate,
+		Password:     password,
+		ExpireDate:   expireDate,
+		PublicUpload: true,
+	})
+}
+
+// RevokeAllShares deletes every share on path, reshares included.
+// It returns the ids of the revoked shares and the errors of the
+// deletions which failed. A share deleted concurrently, which the
+// server reports as not found, is revoked.
+func (c *Client) RevokeAllShares(path string) ([]uint, []error) {
+	return c.deleteSharesForPath(path, true, false)
+}
+
+// RevokeAllSharesWithSubfiles is like RevokeAllShares but also
+// revokes the shares on the direct children of path.
+func (c *Client) RevokeAllSharesWithSubfiles(path string) ([]uint, []error) {
+	return c.deleteSharesForPath(path, true, true)
+}
+
+// deleteSharesForPath deletes the shares on path, those of the other
+// users if includeReshares is true, and those on its direct children
+// if includeSubfiles is true. It returns the ids of the deleted
+// shares, including the ones already gone, and the errors of the
+// deletions which failed.
+func (c *Client) deleteSharesForPath(path string, includeReshares, includeSubfiles bool) ([]uint, []error) {
+	shares, err := c.GetSharesForPath(path, includeReshares, false)
+	if err != nil {
+		return nil, []error{err}
+	}
+	if includeSubfiles {
+		subShares, err := c.GetSharesForPath(path, includeReshares, true)
+		if err != nil {
+			return nil, []error{err}
+		}
+		shares = append(shares, subShares...)
+	}
+
+	var (
+		deleted []uint
+		errs    []error
+	)
+	for _, share := range shares {
+		_, err := c.DeleteShare(share.Id)
+		// The share was deleted between the listing and now.
+		if ocsErr, ok := err.(*OCSError); ok && ocsErr.StatusCode == http.StatusNotFound {
+			err = nil
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("share %d: %v", share.Id, err))
+			continue
+		}
+		deleted = append(deleted, share.Id)
+	}
+
+	return deleted, errs
+}
+
+// UnshareAll deletes the shares created by the user on path and
+// returns how many were deleted. A share deleted concurrently, which
+// the server reports as not found, is counted as deleted. On error,
+// the remaining shares are still deleted and the first error is
+// returned.
+func (c *Client) UnshareAll(path string) (int, error) {
+	deleted, errs := c.deleteSharesForPath(path, false, false)
+	if len(errs) > 0 {
+		return len(deleted), errs[0]
+	}
+	return len(deleted), nil
+}
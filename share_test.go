@@ -0,0 +1,672 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+)
+
+// newOCSServer returns a test server answering the OCS requests
+// with the data blocks found in responses, keyed by URL path.
+func newOCSServer(responses map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := responses[r.URL.Path]
+		if !ok {
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>failure</status><statuscode>404</statuscode><message>Not found</message></meta><data/></ocs>`)
+			return
+		}
+		fmt.Fprintf(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode><message>OK</message></meta><data>%s</data></ocs>`, data)
+	}))
+}
+
+func (t *testSuite) TestSharedWith() {
+	ts := newOCSServer(map[string]string{
+		"/ocs/v2.php/apps/files_sharing/api/v1/shares": `
+<element><id>1</id><share_type>0</share_type><share_with>bob</share_with><share_with_displayname>bob</share_with_displayname><permissions>1</permissions></element>
+<element><id>2</id><share_type>1</share_type><share_with>staff</share_with><share_with_displayname>Staff</share_with_displayname><permissions>31</permissions></element>
+<element><id>3</id><share_type>3</share_type><token>abc</token><permissions>1</permissions></element>`,
+		"/ocs/v2.php/cloud/users/bob": `<id>bob</id><displayname>Bob Smith</displayname>`,
+	})
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	sharees, err := c.SharedWith("ShareTest")
+	t.Nil(err)
+	t.Equal([]Sharee{
+		{Id: "bob", ShareType: ShareTypeUser, DisplayName: "Bob Smith", Permissions: 1},
+		{Id: "staff", ShareType: ShareTypeGroup, DisplayName: "Staff", Permissions: 31},
+	}, sharees)
+}
+
+func (t *testSuite) TestShareAttributes() {
+	ts := newOCSServer(map[string]string{
+		"/ocs/v2.php/apps/files_sharing/api/v1/shares": `
+<element><id>1</id><share_type>0</share_type><attributes>[{&quot;scope&quot;:&quot;permissions&quot;,&quot;key&quot;:&quot;download&quot;,&quot;value&quot;:false}]</attributes></element>
+<element><id>2</id><share_type>0</share_type><attributes/></element>`,
+		"/ocs/v2.php/apps/files_sharing/api/v1/shares/1": `<id>1</id>`,
+	})
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	result, err := c.GetShare("ShareTest")
+	t.Nil(err)
+	if result != nil && len(result.Elements) == 2 {
+		t.Equal(ShareAttributes{{Scope: "permissions", Key: "download", Value: false}}, result.Elements[0].Attributes)
+		t.Nil(result.Elements[1].Attributes)
+	}
+
+	err = c.SetShareAttributes(1, []ShareAttribute{{Scope: "permissions", Key: "download", Value: true}})
+	t.Nil(err)
+}
+
+func (t *testSuite) TestSetResharingAllowed() {
+	var permissions []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data := `<element><id>1</id><share_type>0</share_type><permissions>19</permissions></element>`
+		if r.Method == "PUT" {
+			r.ParseForm()
+			permissions = append(permissions, r.PostForm.Get("permissions"))
+			data = `<id>1</id>`
+		}
+		fmt.Fprintf(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data>%s</data></ocs>`, data)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	t.Nil(c.SetResharingAllowed(1, false))
+	t.Nil(c.SetResharingAllowed(1, true))
+	t.Equal([]string{"3"}, permissions)
+}
+
+func (t *testSuite) TestDeduplicateShares() {
+	var created int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data := ""
+		switch r.Method {
+		case "GET":
+			if created > 0 {
+				data = `<element><id>1</id><share_type>3</share_type><permissions>1</permissions><url>https://cloud.example.com/s/abc</url></element>`
+			}
+		case "POST":
+			created++
+			data = `<id>1</id><url>https://cloud.example.com/s/abc</url>`
+		}
+		fmt.Fprintf(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data>%s</data></ocs>`, data)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+	c.DeduplicateShares = true
+
+	for i := 0; i < 2; i++ {
+		result, err := c.CreateLegacyShare("ShareTest", ShareTypePublic, "false", 1)
+		t.Nil(err)
+		if result != nil {
+			t.Equal(uint(1), result.Id)
+			t.Equal("https://cloud.example.com/s/abc", result.Url)
+		}
+	}
+	t.Equal(1, created)
+}
+
+func (t *testSuite) TestCreateProtectedFileDropShare() {
+	s := newShareServer()
+	defer s.Close()
+
+	c, err := Dial(s.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	expiry := time.Now().AddDate(1, 0, 0)
+	result, err := c.CreateProtectedFileDropShare("ShareTest", "s3cr3t!pass", expiry)
+	t.Nil(err)
+	if result != nil {
+		t.Equal(uint(1), result.Id)
+	}
+	t.Equal("s3cr3t!pass", s.shares[0].Get("password"))
+	t.Equal(expiry.Format("2006-01-02"), s.shares[0].Get("expireDate"))
+
+	share, err := c.GetShareByID(1)
+	t.Nil(err)
+	t.Equal(int(PermissionCreate), share.Permissions)
+
+	_, err = c.CreateProtectedFileDropShare("ShareTest", "s3cr3t!pass", time.Now().AddDate(0, 0, -1))
+	t.Equal(ErrExpirationInPast, err)
+	t.Equal(1, len(s.shares))
+}
+
+func (t *testSuite) TestRevokeAllShares() {
+	deleted := map[string]bool{}
+	failing := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, status := "", 200
+		switch {
+		case r.Method == "DELETE" && r.URL.Path == "/ocs/v2.php/apps/files_sharing/api/v1/shares/3":
+			status = 404
+		case r.Method == "DELETE" && failing && r.URL.Path == "/ocs/v2.php/apps/files_sharing/api/v1/shares/2":
+			status = 403
+		case r.Method == "DELETE":
+			deleted[r.URL.Path] = true
+		case r.URL.Query().Get("subfiles") == "true":
+			data = `<element><id>3</id></element>`
+		default:
+			data = `<element><id>1</id></element><element><id>2</id></element>`
+		}
+		fmt.Fprintf(w, `<?xml version="1.0"?><ocs><meta><statuscode>%d</statuscode></meta><data>%s</data></ocs>`, status, data)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	revoked, errs := c.RevokeAllShares("ShareTest")
+	t.Equal([]uint{1, 2}, revoked)
+	t.Equal(0, len(errs))
+
+	// Share 3 was deleted concurrently.
+	revoked, errs = c.RevokeAllSharesWithSubfiles("ShareTest")
+	t.Equal([]uint{1, 2, 3}, revoked)
+	t.Equal(0, len(errs))
+
+	failing = true
+	revoked, errs = c.RevokeAllShares("ShareTest")
+	t.Equal([]uint{1}, revoked)
+	t.Equal(1, len(errs))
+}
+
+func (t *testSuite) TestListShares() {
+	ts := newOCSServer(map[string]string{
+		"/ocs/v2.php/apps/files_sharing/api/v1/shares": `
+<element><id>1</id><share_type>3</share_type><path>/Public</path><permissions>1</permissions><url>https://cloud.example.com/s/abc</url><expiration>2030-01-02 00:00:00</expiration></element>
+<element><id>2</id><share_type>0</share_type><path>/Private/test.txt</path><permissions>19</permissions><share_with>bob</share_with></element>`,
+	})
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	shares, err := c.ListShares()
+	t.Nil(err)
+	t.Equal(2, len(shares))
+	if len(shares) == 2 {
+		t.Equal(uint(1), shares[0].Id)
+		t.Equal(ShareTypePublic, shares[0].ShareType)
+		t.Equal("/Public", shares[0].Path)
+		t.Equal("https://cloud.example.com/s/abc", shares[0].Url)
+		t.Equal("2030-01-02 00:00:00", shares[0].Expiration)
+		t.Equal("bob", shares[1].ShareWith)
+		t.Equal(19, shares[1].Permissions)
+	}
+}
+
+func (t *testSuite) TestPasswordProtectedShare() {
+	var password string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		password = r.PostForm.Get("password")
+		if len(password) < 10 {
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>failure</status><statuscode>403</statuscode><message>Password needs to be at least 10 characters long</message></meta><data/></ocs>`)
+			return
+		}
+		fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode><message>OK</message></meta><data><id>7</id></data></ocs>`)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	result, err := c.CreatePasswordProtectedShare("ShareTest", "correct horse battery", 1)
+	t.Nil(err)
+	t.Equal(uint(7), result.Id)
+	t.Equal("correct horse battery", password)
+
+	_, err = c.CreatePasswordProtectedShare("ShareTest", "weak", 1)
+	rejected, ok := err.(*PasswordRejectedError)
+	t.True(ok)
+	if ok {
+		t.Equal("Password needs to be at least 10 characters long", rejected.Message)
+	}
+
+	t.Nil(c.SetSharePassword(7, "another strong one"))
+	_, ok = c.SetSharePassword(7, "weak").(*PasswordRejectedError)
+	t.True(ok)
+}
+
+func (t *testSuite) TestSetShareExpiration() {
+	var expiration string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "PUT":
+			r.ParseForm()
+			expiration = r.PostForm.Get("expireDate") + " 00:00:00"
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data/></ocs>`)
+		case "GET":
+			fmt.Fprintf(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data><element><id>7</id><expiration>%s</expiration></element></data></ocs>`, expiration)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	expiry := time.Now().AddDate(1, 0, 0)
+	t.Nil(c.SetShareExpiration(7, expiry))
+	result, err := c.GetShare("ShareTest")
+	t.Nil(err)
+	t.Equal(expiry.Format("2006-01-02")+" 00:00:00", result.Elements[0].Expiration)
+
+	t.Equal(ErrExpirationInPast, c.SetShareExpiration(7, time.Now().AddDate(0, 0, -1)))
+	_, err = c.CreateExpiringShare("ShareTest", 1, time.Now().AddDate(0, 0, -1))
+	t.Equal(ErrExpirationInPast, err)
+}
+
+func (t *testSuite) TestUpdateSharePermissions() {
+	permissions := "1"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.Method {
+		case "POST":
+			permissions = r.PostForm.Get("permissions")
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data><id>7</id></data></ocs>`)
+		case "PUT":
+			t.Equal("/ocs/v2.php/apps/files_sharing/api/v1/shares/7", r.URL.Path)
+			permissions = r.PostForm.Get("permissions")
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data/></ocs>`)
+		case "GET":
+			fmt.Fprintf(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data><element><id>7</id><permissions>%s</permissions></element></data></ocs>`, permissions)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	result, err := c.CreateLegacyShare("ShareTest", ShareTypePublic, "false", int(PermissionRead))
+	t.Nil(err)
+
+	readWrite := PermissionRead | PermissionUpdate | PermissionCreate | PermissionDelete
+	t.Nil(c.UpdateSharePermissions(result.Id, readWrite))
+	result, err = c.GetShare("ShareTest")
+	t.Nil(err)
+	t.Equal(int(readWrite), result.Elements[0].Permissions)
+}
+
+func (t *testSuite) TestShareWithUserAndGroup() {
+	var created []url.Values
+	var deleted []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.Method {
+		case "POST":
+			created = append(created, r.PostForm)
+			fmt.Fprintf(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data><id>%d</id></data></ocs>`, len(created))
+		case "DELETE":
+			deleted = append(deleted, r.URL.Path)
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data/></ocs>`)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	user, err := c.ShareWithUser("ShareTest", "bob", PermissionRead)
+	t.Nil(err)
+	group, err := c.ShareWithGroup("ShareTest", "staff", PermissionAll)
+	t.Nil(err)
+	t.Equal(url.Values{"path": {"ShareTest"}, "shareType": {"0"}, "shareWith": {"bob"}, "permissions": {"1"}}, created[0])
+	t.Equal(url.Values{"path": {"ShareTest"}, "shareType": {"1"}, "shareWith": {"staff"}, "permissions": {"31"}}, created[1])
+
+	_, err = c.DeleteShare(user.Id)
+	t.Nil(err)
+	_, err = c.DeleteShare(group.Id)
+	t.Nil(err)
+	t.Equal([]string{
+		"/ocs/v2.php/apps/files_sharing/api/v1/shares/1",
+		"/ocs/v2.php/apps/files_sharing/api/v1/shares/2",
+	}, deleted)
+}
+
+func (t *testSuite) TestUnshareAll() {
+	var deleted []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			t.Equal("ShareTest", r.URL.Query().Get("path"))
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data><element><id>1</id></element><element><id>2</id></element><element><id>3</id></element></data></ocs>`)
+		case "DELETE":
+			deleted = append(deleted, path.Base(r.URL.Path))
+			if r.URL.Path == "/ocs/v2.php/apps/files_sharing/api/v1/shares/2" {
+				fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>failure</status><statuscode>404</statuscode><message>Wrong share ID, share does not exist</message></meta><data/></ocs>`)
+				return
+			}
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data/></ocs>`)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	n, err := c.UnshareAll("ShareTest")
+	t.Nil(err)
+	t.Equal(3, n)
+	t.Equal([]string{"1", "2", "3"}, deleted)
+}
+
+func (t *testSuite) TestShareResult() {
+	ts := newOCSServer(map[string]string{
+		"/ocs/v2.php/apps/files_sharing/api/v1/shares/1": `<id>1</id><share_type>3</share_type><permissions>4</permissions><path>/ShareTest</path><token>abc</token><url>https://cloud.example.com/s/abc</url><expiration>2030-01-02 00:00:00</expiration>`,
+		"/ocs/v2.php/apps/files_sharing/api/v1/shares": `
+<element><id>2</id><share_type>3</share_type><permissions>1</permissions><path>/ShareTest</path><token>def</token><url>https://cloud.example.com/s/def</url><expiration/></element>
+<element><id>3</id><share_type>0</share_type><permissions>31</permissions><path>/ShareTest</path><share_with>bob</share_with></element>`,
+		"/ocs/v2.php/apps/files_sharing/api/v1/shares/2": `
+<element><id>2</id><share_type>3</share_type><permissions>1</permissions><path>/ShareTest</path><token>def</token><url>https://cloud.example.com/s/def</url></element>`,
+	})
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	result, err := c.sendSharesRequest(context.Background(), "PUT", "shares/1", "permissions=4")
+	t.Nil(err)
+	t.Equal(uint(1), result.Id)
+	t.Equal("https://cloud.example.com/s/abc", result.Url)
+	t.Equal(1, len(result.Elements))
+	if len(result.Elements) == 1 {
+		share := result.Elements[0]
+		t.Equal(uint(1), share.Id)
+		t.Equal(ShareTypePublic, share.ShareType)
+		t.Equal(4, share.Permissions)
+		t.Equal("/ShareTest", share.Path)
+		t.Equal("abc", share.Token)
+		t.Equal("https://cloud.example.com/s/abc", share.Url)
+		t.Equal(time.Date(2030, 1, 2, 0, 0, 0, 0, time.Local), share.ExpireDate())
+	}
+
+	result, err = c.GetShare("ShareTest")
+	t.Nil(err)
+	t.Equal(uint(0), result.Id)
+	t.Equal("", result.Url)
+	t.Equal(2, len(result.Elements))
+	if len(result.Elements) == 2 {
+		t.Equal("def", result.Elements[0].Token)
+		t.True(result.Elements[0].ExpireDate().IsZero())
+		t.Equal("bob", result.Elements[1].ShareWith)
+	}
+
+	result, err = c.sendSharesRequest(context.Background(), "GET", "shares/2", "")
+	t.Nil(err)
+	t.Equal(uint(2), result.Id)
+	t.Equal("https://cloud.example.com/s/def", result.Url)
+	t.Equal(1, len(result.Elements))
+}
+
+func (t *testSuite) TestGetShareByID() {
+	ts := newOCSServer(map[string]string{
+		"/ocs/v2.php/apps/files_sharing/api/v1/shares/7": `
+<element><id>7</id><share_type>0</share_type><permissions>31</permissions><path>/ShareTest</path><share_with>bob</share_with><expiration>2030-01-02 00:00:00</expiration></element>`,
+	})
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	share, err := c.GetShareByID(7)
+	t.Nil(err)
+	if share != nil {
+		t.Equal(uint(7), share.Id)
+		t.Equal(31, share.Permissions)
+		t.Equal("bob", share.ShareWith)
+		t.Equal("2030-01-02 00:00:00", share.Expiration)
+	}
+
+	_, err = c.GetShareByID(8)
+	t.Equal(ErrShareNotFound, err)
+}
+
+func (t *testSuite) TestShareByEmail() {
+	var form url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		form = r.PostForm
+		if form.Get("shareWith") == "nomail@example.com" {
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>failure</status><statuscode>404</statuscode><message>Failed to send share by email</message></meta><data/></ocs>`)
+			return
+		}
+		fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data><id>9</id><share_type>4</share_type><permissions>1</permissions><path>/ShareTest</path><share_with>alice@example.com</share_with><token>xyz</token><url>https://cloud.example.com/s/xyz</url></data></ocs>`)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	share, err := c.ShareByEmail("ShareTest", "alice@example.com", PermissionRead)
+	t.Nil(err)
+	t.Equal(url.Values{
+		"path":        {"ShareTest"},
+		"shareType":   {"4"},
+		"shareWith":   {"alice@example.com"},
+		"permissions": {"1"},
+	}, form)
+	if share != nil {
+		t.Equal(uint(9), share.Id)
+		t.Equal(ShareTypeEmail, share.ShareType)
+		t.Equal("alice@example.com", share.ShareWith)
+		t.Equal("xyz", share.Token)
+	}
+
+	_, err = c.ShareByEmailProtected("ShareTest", "alice@example.com", PermissionRead, "s3cr3t!pass", "Here it is")
+	t.Nil(err)
+	t.Equal("s3cr3t!pass", form.Get("password"))
+	t.Equal("Here it is", form.Get("note"))
+
+	_, err = c.ShareByEmail("ShareTest", "nomail@example.com", PermissionRead)
+	t.Equal(&OCSError{StatusCode: 404, Message: "Failed to send share by email"}, err)
+}
+
+func (t *testSuite) TestShareNote() {
+	note := "please review by Friday"
+	var form url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.Method {
+		case "POST", "PUT":
+			form = r.PostForm
+			note = form.Get("note")
+			fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data><id>7</id></data></ocs>`)
+		case "GET":
+			fmt.Fprintf(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data><element><id>7</id><note>%s</note></element></data></ocs>`, note)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	expiry := time.Now().AddDate(1, 0, 0)
+	_, err = c.CreateShare("ShareTest", ShareOptions{
+		ShareType:   ShareTypeUser,
+		ShareWith:   "bob",
+		Permissions: PermissionRead,
+		ExpireDate:  expiry,
+		Note:        note,
+	})
+	t.Nil(err)
+	t.Equal(url.Values{
+		"path":        {"ShareTest"},
+		"shareType":   {"0"},
+		"shareWith":   {"bob"},
+		"permissions": {"1"},
+		"expireDate":  {expiry.Format("2006-01-02")},
+		"note":        {"please review by Friday"},
+	}, form)
+
+	result, err := c.GetShare("ShareTest")
+	t.Nil(err)
+	t.Equal("please review by Friday", result.Elements[0].Note)
+
+	t.Nil(c.SetShareNote(7, "done"))
+	share, err := c.GetShareByID(7)
+	t.Nil(err)
+	t.Equal("done", share.Note)
+}
+
+// shareServer is an in-memory share API. Like the server, it resets
+// the permissions of a link with public upload to 15.
+type shareServer struct {
+	*httptest.Server
+
+	// shares are the forms which created the shares, updated
+	// since, along with their id, which is their index plus one.
+	shares   []url.Values
+	requests []string
+}
+
+func newShareServer() *shareServer {
+	s := &shareServer{}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+func (s *shareServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	name := path.Base(r.URL.Path)
+	s.requests = append(s.requests, r.Method+" "+name+" "+r.PostForm.Encode())
+
+	var share url.Values
+	if id, err := strconv.Atoi(name); err == nil && id > 0 && id <= len(s.shares) {
+		share = s.shares[id-1]
+	}
+	var data string
+	switch {
+	case r.Method == "POST":
+		share = url.Values{"id": {strconv.Itoa(len(s.shares) + 1)}}
+		for key := range r.PostForm {
+			share.Set(key, r.PostForm.Get(key))
+		}
+		if share.Get("publicUpload") == "true" {
+			share.Set("permissions", "15")
+		}
+		s.shares = append(s.shares, share)
+		data = shareXML(share)
+	case r.Method == "GET" && name == "shares":
+		for _, share := range s.shares {
+			if share.Get("path") == r.URL.Query().Get("path") {
+				data += "<element>" + shareXML(share) + "</element>"
+			}
+		}
+	case share == nil:
+		fmt.Fprint(w, `<?xml version="1.0"?><ocs><meta><status>failure</status><statuscode>404</statuscode></meta><data/></ocs>`)
+		return
+	case r.Method == "PUT":
+		for key := range r.PostForm {
+			share.Set(key, r.PostForm.Get(key))
+		}
+		data = shareXML(share)
+	case r.Method == "GET":
+		data = "<element>" + shareXML(share) + "</element>"
+	}
+	fmt.Fprintf(w, `<?xml version="1.0"?><ocs><meta><status>ok</status><statuscode>200</statuscode></meta><data>%s</data></ocs>`, data)
+}
+
+// shareXML returns the data element of the response of the share API
+// describing share.
+func shareXML(share url.Values) string {
+	return fmt.Sprintf(`<id>%s</id><share_type>%s</share_type><share_with>%s</share_with><permissions>%s</permissions><path>%s</path><note>%s</note>`,
+		share.Get("id"), share.Get("shareType"), share.Get("shareWith"), share.Get("permissions"), share.Get("path"), share.Get("note"))
+}
+
+func (t *testSuite) TestCreateShare() {
+	s := newShareServer()
+	defer s.Close()
+
+	c, err := Dial(s.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	share, err := c.CreateShare("ShareTest", ShareOptions{
+		ShareType:    ShareTypePublic,
+		Permissions:  PermissionRead | PermissionCreate,
+		Password:     "s3cr3t!pass",
+		PublicUpload: true,
+		HideDownload: true,
+	})
+	t.Nil(err)
+	if share != nil {
+		t.Equal(uint(1), share.Id)
+		t.Equal(5, share.Permissions)
+	}
+
+	_, err = c.CreateFileDropShare("ShareTest")
+	t.Nil(err)
+	_, err = c.CreateReadOnlyShare("ShareTest")
+	t.Nil(err)
+	t.Equal([]string{
+		"POST shares password=s3cr3t%21pass&path=ShareTest&permissions=5&publicUpload=true&shareType=3",
+		"PUT 1 hideDownload=true&permissions=5",
+		"POST shares path=ShareTest&permissions=4&publicUpload=true&shareType=3",
+		"PUT 2 permissions=4",
+		"POST shares path=ShareTest&permissions=1&shareType=3",
+	}, s.requests)
+
+	for id, permissions := range map[uint]int{1: 5, 2: 4, 3: 1} {
+		share, err := c.GetShareByID(id)
+		t.Nil(err)
+		t.Equal(permissions, share.Permissions)
+	}
+}
+
+func (t *testSuite) TestDeduplicateProtectedShares() {
+	s := newShareServer()
+	defer s.Close()
+
+	c, err := Dial(s.URL+"/", "admin", "password")
+	t.Nil(err)
+	c.DeduplicateShares = true
+
+	opts := ShareOptions{ShareType: ShareTypePublic, Permissions: PermissionRead}
+	_, err = c.CreateShare("ShareTest", opts)
+	t.Nil(err)
+	_, err = c.CreateShare("ShareTest", opts)
+	t.Nil(err)
+	t.Equal(1, len(s.shares))
+
+	opts.Password = "s3cr3t!pass"
+	share, err := c.CreateShare("ShareTest", opts)
+	t.Nil(err)
+	t.Equal(2, len(s.shares))
+	if share != nil {
+		t.Equal(uint(2), share.Id)
+	}
+	t.Equal("s3cr3t!pass", s.shares[1].Get("password"))
+}
+
+func (t *testSuite) TestCreateExpiringShare() {
+	s := newShareServer()
+	defer s.Close()
+
+	c, err := Dial(s.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	expiry := time.Now().AddDate(0, 1, 0)
+	_, err = c.CreateExpiringShare("ShareTest", PermissionRead, expiry)
+	t.Nil(err)
+	t.Equal([]string{
+		"POST shares expireDate=" + expiry.Format("2006-01-02") + "&path=ShareTest&permissions=1&shareType=3",
+	}, s.requests)
+
+	_, err = c.CreateExpiringShare("ShareTest", PermissionRead, time.Time{})
+	t.Equal(ErrExpirationInPast, err)
+	t.Equal(1, len(s.requests))
+}
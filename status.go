@@ -0,0 +1,143 @@
+package cloud
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Server flavors returned by ServerFlavor.
+const (
+	FlavorNextcloud = "nextcloud"
+	FlavorOwnCloud  = "owncloud"
+)
+
+// ErrNotSupported is returned when an operation is not supported by
+// the server flavor, e.g. group folders on ownCloud.
+var ErrNotSupported = errors.New("operation not supported by the server")
+
+// ServerStatus is the status of the server, as reported by
+// status.php.
+type ServerStatus struct {
+	Installed      bool `json:"installed"`
+	Maintenance    bool `json:"maintenance"`
+	NeedsDbUpgrade bool `json:"needsDbUpgrade"`
+
+	// Version is the full version number, e.g. "28.0.1.1", while
+	// VersionString is the one displayed to users, e.g. "28.0.1".
+	Version       string `json:"version"`
+	VersionString string `json:"versionstring"`
+
+	Edition     string `json:"edition"`
+	ProductName string `json:"productname"`
+
+	// Product is reported by ownCloud only.
+	Product string `json:"product"`
+}
+
+// UnmarshalJSON decodes the content of status.php. ownCloud reports
+// the boolean fields as strings.
+func (s *ServerStatus) UnmarshalJSON(data []byte) error {
+	type status ServerStatus
+	aux := struct {
+		*status
+		Installed      stringBool `json:"installed"`
+		Maintenance    stringBool `json:"maintenance"`
+		NeedsDbUpgrade stringBool `json:"needsDbUpgrade"`
+	}{status: (*status)(s)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	s.Installed = bool(aux.Installed)
+	s.Maintenance = bool(aux.Maintenance)
+	s.NeedsDbUpgrade = bool(aux.NeedsDbUpgrade)
+	return nil
+}
+
+// stringBool is a boolean encoded either as a JSON boolean or as a
+// string.
+type stringBool bool
+
+func (b *stringBool) UnmarshalJSON(data []byte) error {
+	v, err := strconv.ParseBool(strings.Trim(string(data), `"`))
+	if err != nil {
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	*b = stringBool(v)
+	return nil
+}
+
+// Status returns the status of the server. It doesn't require
+// valid credentials, so it can be used to check that the server is
+// reachable.
+func (c *Client) Status() (*ServerStatus, error) {
+	req, err := c.newRequest("GET", "status.php", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(resp)
+	}
+
+	status := ServerStatus{}
+	err = json.NewDecoder(resp.Body).Decode(&status)
+	if err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// ServerFlavor tells whether the server runs Nextcloud or ownCloud,
+// returning FlavorNextcloud or FlavorOwnCloud along with the server
+// version. The result is cached by the client.
+func (c *Client) ServerFlavor() (flavor string, version string, err error) {
+	c.flavorMu.Lock()
+	defer c.flavorMu.Unlock()
+
+	if c.flavor != "" {
+		return c.flavor, c.version, nil
+	}
+
+	status, err := c.Status()
+	if err != nil {
+		return "", "", err
+	}
+
+	// Only ownCloud reports the product field, while Nextcloud
+	// allows the administrator to rebrand the product name.
+	if status.Product != "" || strings.EqualFold(status.ProductName, "owncloud") {
+		c.flavor = FlavorOwnCloud
+	} else {
+		c.flavor = FlavorNextcloud
+	}
+	c.version = status.VersionString
+	if c.version == "" {
+		c.version = status.Version
+	}
+
+	return c.flavor, c.version, nil
+}
+
+// requireFlavor returns ErrNotSupported if the server flavor is not
+// the given one.
+func (c *Client) requireFlavor(flavor string) error {
+	f, _, err := c.ServerFlavor()
+	if err != nil {
+		return err
+	}
+	if f != flavor {
+		return ErrNotSupported
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package cloud
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+func (t *testSuite) TestServerFlavor() {
+	var status string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, status)
+	}))
+	defer ts.Close()
+
+	status = `{"installed":"true","maintenance":"false","version":"10.13.0.1","versionstring":"10.13.0","edition":"Community","productname":"ownCloud","product":"ownCloud"}`
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	flavor, version, err := c.ServerFlavor()
+	t.Nil(err)
+	t.Equal(FlavorOwnCloud, flavor)
+	t.Equal("10.13.0", version)
+
+	_, err = c.CreateGroupFolder("GroupFolder")
+	t.Equal(ErrNotSupported, err)
+
+	status = `{"installed":true,"maintenance":false,"version":"28.0.1.1","versionstring":"28.0.1","edition":"","productname":"ACME Cloud"}`
+	c, err = Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	flavor, version, err = c.ServerFlavor()
+	t.Nil(err)
+	t.Equal(FlavorNextcloud, flavor)
+	t.Equal("28.0.1", version)
+
+	flavor, _, err = client.ServerFlavor()
+	t.Nil(err)
+	t.Equal(FlavorNextcloud, flavor)
+}
+
+func (t *testSuite) TestStatus() {
+	var status string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, status)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	status = `{"installed":true,"maintenance":false,"needsDbUpgrade":false,"version":"28.0.1.1","versionstring":"28.0.1","edition":"","productname":"Nextcloud","extendedSupport":false}`
+	s, err := c.Status()
+	t.Nil(err)
+	t.Equal(&ServerStatus{
+		Installed:     true,
+		Version:       "28.0.1.1",
+		VersionString: "28.0.1",
+		ProductName:   "Nextcloud",
+	}, s)
+
+	status = `{"installed":"true","maintenance":"true","version":"10.13.0.1","versionstring":"10.13.0","edition":"Community","productname":"ownCloud","product":"ownCloud"}`
+	s, err = c.Status()
+	t.Nil(err)
+	t.True(s.Installed)
+	t.True(s.Maintenance)
+	t.Equal("ownCloud", s.Product)
+}
@@ -0,0 +1,202 @@
+package cloud
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+)
+
+// batchWorkers is the number of requests sent concurrently by the
+// batch methods.
+const batchWorkers = 4
+
+// ErrTagsUnavailable is returned when the systemtags app is not
+// enabled on the server.
+var ErrTagsUnavailable = errors.New("system tags are not available")
+
+// Tag is a collaborative system tag.
+type Tag struct {
+	Id   string
+	Name string
+
+	// Visible and Assignable tell whether users who are not
+	// administrators can see the tag and assign it to files.
+	Visible    bool
+	Assignable bool
+}
+
+// CreateTag creates a system tag with the given name and returns its
+// id. visible and assignable have the meaning of the fields of Tag.
+func (c *Client) CreateTag(name string, visible, assignable bool) (string, error) {
+	body, err := json.Marshal(struct {
+		Name           string `json:"name"`
+		UserVisible    bool   `json:"userVisible"`
+		UserAssignable bool   `json:"userAssignable"`
+	}{name, visible, assignable})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := c.newRequest("POST", "remote.php/dav/systemtags", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+	case http.StatusNotFound:
+		return "", ErrTagsUnavailable
+	default:
+		return "", newStatusError(resp)
+	}
+
+	// The location of the new tag ends with its id.
+	location := resp.Header.Get("Content-Location")
+	if location == "" {
+		return "", fmt.Errorf("POST %s: no location for the tag %s", redactURL(req.URL), name)
+	}
+	return path.Base(location), nil
+}
+
+// ListTags returns the system tags assigned to the file at p.
+func (c *Client) ListTags(p string) ([]Tag, error) {
+	fileId, err := c.fileId(p)
+	if err != nil {
+		return nil, err
+	}
+
+	root := path.Join("remote.php/dav/systemtags-relations/files", fileId)
+	result, err := c.davPropfind(root, "1", "<oc:id/><oc:display-name/><oc:user-visible/><oc:user-assignable/>")
+	if statusErr, ok := err.(*StatusError); ok && statusErr.StatusCode == http.StatusNotFound {
+		return nil, ErrTagsUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []Tag
+	for _, response := range result.Responses {
+		prop := response.prop()
+		// The first response is the file itself, which has no
+		// tag id.
+		if prop.TagId == "" {
+			continue
+		}
+		tags = append(tags, Tag{
+			Id:         prop.TagId,
+			Name:       prop.TagName,
+			Visible:    prop.TagUserVisible,
+			Assignable: prop.TagUserAssignable,
+		})
+	}
+
+	return tags, nil
+}
+
+// TagAssignment associates a file with a system tag.
+type TagAssignment struct {
+	Path  string
+	TagId string
+}
+
+// AssignTag assigns the system tag with the given id to the file at
+// p. Assigning a tag twice is not an error.
+func (c *Client) AssignTag(p string, tagId string) error {
+	fileId, err := c.fileId(p)
+	if err != nil {
+		return err
+	}
+
+	req, err := c.newRequest("PUT", path.Join("remote.php/dav/systemtags-relations/files", fileId, tagId), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated:
+	case http.StatusConflict:
+		// The tag is already assigned.
+	case http.StatusNotFound:
+		return ErrTagsUnavailable
+	default:
+		return newStatusError(resp)
+	}
+
+	return nil
+}
+
+// AssignTags performs the given assignments. The server has no batch
+// endpoint for tags, so the requests are sent concurrently by at
+// most batchWorkers goroutines. The returned slice is aligned with
+// assignments: the i-th error is the outcome of the i-th assignment
+// and is nil on success.
+func (c *Client) AssignTags(assignments []TagAssignment) []error {
+	errs := make([]error, len(assignments))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < batchWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = c.AssignTag(assignments[i].Path, assignments[i].TagId)
+			}
+		}()
+	}
+	for i := range assignments {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return errs
+}
+
+// ListFilesByTag returns the files carrying the system tag with the
+// given id. The search is performed by the server.
+func (c *Client) ListFilesByTag(tagId string) ([]FileInfo, error) {
+	var rule bytes.Buffer
+	rule.WriteString("<oc:systemtag>")
+	xml.EscapeText(&rule, []byte(tagId))
+	rule.WriteString("</oc:systemtag>")
+
+	return c.filterFiles(rule.String())
+}
+
+// filterFiles returns the files of the user matching the rules of a
+// filter-files REPORT.
+func (c *Client) filterFiles(rules string) ([]FileInfo, error) {
+	result, err := c.report(c.filesRoot(), rules, fileInfoProps)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]FileInfo, 0, len(result.Responses))
+	for i := range result.Responses {
+		info, err := c.fileInfo(&result.Responses[i], c.filesRoot())
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, info)
+	}
+	return files, nil
+}
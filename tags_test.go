@@ -0,0 +1,151 @@
+package cloud
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+func (t *testSuite) TestAssignTags() {
+	var mu sync.Mutex
+	assigned := map[string]bool{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "PROPFIND":
+			if strings.HasSuffix(r.URL.Path, "missing.txt") {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusMultiStatus)
+			fmt.Fprintf(w, `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns">
+ <d:response>
+  <d:href>%s</d:href>
+  <d:propstat><d:prop><oc:fileid>%d</oc:fileid></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+</d:multistatus>`, r.URL.Path, len(r.URL.Path))
+		case "PUT":
+			mu.Lock()
+			assigned[r.URL.Path] = true
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	errs := c.AssignTags([]TagAssignment{
+		{Path: "a.txt", TagId: "1"},
+		{Path: "missing.txt", TagId: "1"},
+		{Path: "Folder/b.txt", TagId: "2"},
+	})
+	t.Equal(3, len(errs))
+	t.Nil(errs[0])
+	t.NotNil(errs[1])
+	t.Nil(errs[2])
+	t.True(assigned["/remote.php/dav/systemtags-relations/files/24/1"])
+	t.True(assigned["/remote.php/dav/systemtags-relations/files/31/2"])
+}
+
+func (t *testSuite) TestListFilesByTag() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		t.Equal("REPORT", r.Method)
+		t.Equal("/remote.php/dav/files/admin", r.URL.Path)
+		t.True(strings.Contains(string(body), "<oc:systemtag>5</oc:systemtag>"))
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprint(w, `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns" xmlns:nc="http://nextcloud.org/ns">
+ <d:response>
+  <d:href>/remote.php/dav/files/admin/Test/My%20File.txt</d:href>
+  <d:propstat><d:prop><d:resourcetype/><d:getcontentlength>13</d:getcontentlength><d:getlastmodified>Mon, 02 Jan 2006 15:04:05 GMT</d:getlastmodified><d:getcontenttype>text/plain</d:getcontenttype><nc:creation_time>1136214245</nc:creation_time><nc:upload_time>1136214300</nc:upload_time></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+  <d:propstat><d:prop><oc:size/></d:prop><d:status>HTTP/1.1 404 Not Found</d:status></d:propstat>
+ </d:response>
+ <d:response>
+  <d:href>/remote.php/dav/files/admin/Folder/</d:href>
+  <d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype><oc:size>42</oc:size></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+</d:multistatus>`)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	files, err := c.ListFilesByTag("5")
+	t.Nil(err)
+	t.Equal([]FileInfo{
+		{
+			Name:         "My File.txt",
+			Path:         "/Test/My File.txt",
+			Href:         "/remote.php/dav/files/admin/Test/My%20File.txt",
+			Size:         13,
+			ModTime:      time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+			CreationTime: time.Unix(1136214245, 0),
+			UploadTime:   time.Unix(1136214300, 0),
+			ContentType:  "text/plain",
+		},
+		{Name: "Folder", Path: "/Folder", Href: "/remote.php/dav/files/admin/Folder/", Size: 42, IsDir: true},
+	}, files)
+}
+
+func (t *testSuite) TestTags() {
+	available := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available && strings.Contains(r.URL.Path, "/systemtags") {
+			http.NotFound(w, r)
+			return
+		}
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/remote.php/dav/systemtags":
+			body, _ := ioutil.ReadAll(r.Body)
+			t.Equal(`{"name":"Invoices","userVisible":true,"userAssignable":false}`, string(body))
+			w.Header().Set("Content-Location", "/remote.php/dav/systemtags/7")
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == "PROPFIND" && r.URL.Path == "/remote.php/webdav/Test/test.txt":
+			w.WriteHeader(http.StatusMultiStatus)
+			fmt.Fprint(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns"><d:response><d:href>/remote.php/webdav/Test/test.txt</d:href><d:propstat><d:prop><oc:fileid>42</oc:fileid></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response></d:multistatus>`)
+		case r.Method == "PROPFIND" && r.URL.Path == "/remote.php/dav/systemtags-relations/files/42":
+			w.WriteHeader(http.StatusMultiStatus)
+			fmt.Fprint(w, `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns">
+ <d:response>
+  <d:href>/remote.php/dav/systemtags-relations/files/42/</d:href>
+  <d:propstat><d:prop/><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+  <d:propstat><d:prop><oc:id/><oc:display-name/></d:prop><d:status>HTTP/1.1 404 Not Found</d:status></d:propstat>
+ </d:response>
+ <d:response>
+  <d:href>/remote.php/dav/systemtags-relations/files/42/7</d:href>
+  <d:propstat><d:prop><oc:id>7</oc:id><oc:display-name>Invoices</oc:display-name><oc:user-visible>true</oc:user-visible><oc:user-assignable>false</oc:user-assignable></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+</d:multistatus>`)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	id, err := c.CreateTag("Invoices", true, false)
+	t.Nil(err)
+	t.Equal("7", id)
+
+	tags, err := c.ListTags("Test/test.txt")
+	t.Nil(err)
+	t.Equal([]Tag{{Id: "7", Name: "Invoices", Visible: true}}, tags)
+
+	available = false
+	_, err = c.CreateTag("Invoices", true, true)
+	t.Equal(ErrTagsUnavailable, err)
+	_, err = c.ListTags("Test/test.txt")
+	t.Equal(ErrTagsUnavailable, err)
+	t.Equal(ErrTagsUnavailable, c.AssignTag("Test/test.txt", "7"))
+}
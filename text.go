@@ -0,0 +1,76 @@
+package cloud
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	bomUTF8    = []byte{0xef, 0xbb, 0xbf}
+	bomUTF16LE = []byte{0xff, 0xfe}
+	bomUTF16BE = []byte{0xfe, 0xff}
+)
+
+// ReadText downloads the text file at path and returns its content
+// as a string. UTF-8 byte order marks are stripped and UTF-16 files
+// with a byte order mark are decoded. Content which isn't valid
+// UTF-8 is decoded as ISO-8859-1.
+func (c *Client) ReadText(path string) (string, error) {
+	data, err := c.Download(path)
+	if err != nil {
+		return "", err
+	}
+	return decodeText(data), nil
+}
+
+// WriteText uploads content to path as a UTF-8 text/plain file.
+func (c *Client) WriteText(path, content string) error {
+	if !utf8.ValidString(content) {
+		return errors.New("content is not valid UTF-8")
+	}
+
+	req, err := c.newWebDavRequest("PUT", path, strings.NewReader(content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	start := time.Now()
+	err = c.sendUpload(req, nil)
+	c.emit(OpUpload, path, int64(len(content)), start, err)
+	return err
+}
+
+func decodeText(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return string(data[len(bomUTF8):])
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return decodeUTF16(data[len(bomUTF16LE):], false)
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return decodeUTF16(data[len(bomUTF16BE):], true)
+	case utf8.Valid(data):
+		return string(data)
+	}
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
+
+func decodeUTF16(data []byte, bigEndian bool) string {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,30 @@
+package cloud
+
+func (t *testSuite) TestReadWriteText() {
+	s := newDavServer()
+	defer s.Close()
+	c := s.client()
+
+	err := c.WriteText("test.txt", "Ciao mondo! àèìòù\n")
+	t.Nil(err)
+	text, err := c.ReadText("test.txt")
+	t.Nil(err)
+	t.Equal("Ciao mondo! àèìòù\n", text)
+
+	err = c.WriteText("test.txt", "\xff")
+	t.NotNil(err)
+
+	for content, expected := range map[string]string{
+		"\xef\xbb\xbfHello":     "Hello",
+		"\xff\xfeH\x00i\x00":    "Hi",
+		"\xfe\xff\x00H\x00i":    "Hi",
+		"caf\xe9":               "café",
+		"Hello World!\n":        "Hello World!\n",
+		"\xef\xbb\xbfàèìòù\r\n": "àèìòù\r\n",
+	} {
+		s.files["/test.txt"] = []byte(content)
+		text, err := c.ReadText("test.txt")
+		t.Nil(err)
+		t.Equal(expected, text)
+	}
+}
@@ -0,0 +1,41 @@
+package cloud
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+)
+
+// DialInsecure is like Dial but doesn't verify the certificate of
+// the server, e.g. a self-signed one.
+//
+// This is meant for testing only: without verification, anyone able
+// to intercept the connection can impersonate the server and read
+// the credentials and the files. To trust a self-signed certificate
+// or an internal certificate authority, use DialWithRootCAs instead.
+func DialInsecure(host, username, password string) (*Client, error) {
+	return dialTLS(host, username, password, &tls.Config{InsecureSkipVerify: true})
+}
+
+// DialWithRootCAs is like Dial but verifies the certificate of the
+// server against roots instead of the certificate authorities of the
+// system. roots may hold the certificate of an internal authority,
+// or a self-signed certificate of the server.
+func DialWithRootCAs(host, username, password string, roots *x509.CertPool) (*Client, error) {
+	return dialTLS(host, username, password, &tls.Config{RootCAs: roots})
+}
+
+// dialTLS is like Dial, setting an HTTPClient whose connections use
+// config.
+func dialTLS(host, username, password string, config *tls.Config) (*Client, error) {
+	c, err := Dial(host, username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := defaultTransport.Clone()
+	transport.TLSClientConfig = config
+	c.HTTPClient = &http.Client{Transport: transport}
+
+	return c, nil
+}
@@ -0,0 +1,41 @@
+package cloud
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+func (t *testSuite) TestDialTLS() {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "Hello World!\n")
+	}))
+	defer ts.Close()
+
+	// The self-signed certificate of the server is rejected by
+	// default.
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+	_, err = c.Download("test.txt")
+	t.NotNil(err)
+
+	c, err = DialInsecure(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+	data, err := c.Download("test.txt")
+	t.Nil(err)
+	t.Equal("Hello World!\n", string(data))
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ts.Certificate())
+	c, err = DialWithRootCAs(ts.URL+"/", "admin", "password", roots)
+	t.Nil(err)
+	data, err = c.Download("test.txt")
+	t.Nil(err)
+	t.Equal("Hello World!\n", string(data))
+
+	c, err = DialWithRootCAs(ts.URL+"/", "admin", "password", x509.NewCertPool())
+	t.Nil(err)
+	_, err = c.Download("test.txt")
+	t.NotNil(err)
+}
@@ -0,0 +1,243 @@
+package cloud
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Transfer copies the file at srcPath on c to destPath on dest,
+// which may be another server. The content is streamed from the
+// download to the upload, so it is never held entirely in memory nor
+// staged on disk.
+func (c *Client) Transfer(dest *Client, srcPath, destPath string) error {
+	pr, pw := io.Pipe()
+
+	start := time.Now()
+	type result struct {
+		n   int64
+		err error
+	}
+	downloaded := make(chan result, 1)
+	go func() {
+		n, err := c.downloadTo(srcPath, pw, nil)
+		pw.CloseWithError(err)
+		downloaded <- result{n, err}
+	}()
+
+	counter := &countingReader{r: pr}
+	err := dest.uploadFrom(destPath, counter, -1, nil)
+	// Unblock the download if the upload stopped reading early.
+	pr.CloseWithError(errUploadStopped)
+
+	download := <-downloaded
+	// The download is aborted by the failure of the upload.
+	aborted := download.err == errUploadStopped && err != nil
+	if aborted {
+		download.err = err
+	}
+	c.emit(OpDownload, srcPath, download.n, start, download.err)
+	dest.emit(OpUpload, destPath, counter.count(), start, err)
+
+	// Otherwise a failed download makes the upload fail too.
+	if download.err != nil && !aborted {
+		return download.err
+	}
+	return err
+}
+
+// errUploadStopped aborts the download of a Transfer whose upload
+// stopped reading.
+var errUploadStopped = errors.New("upload stopped")
+
+// DownloadTo writes the content of the file at path to w and returns
+// the number of bytes written. The content is streamed, so it is
+// never held entirely in memory. Nothing is written to w if the
+// server reports an error, but a failure during the transfer may
+// leave w with part of the content; the error is returned then.
+func (c *Client) DownloadTo(path string, w io.Writer) (int64, error) {
+	start := time.Now()
+	n, err := c.downloadTo(path, w, nil)
+	c.emit(OpDownload, path, n, start, err)
+	return n, err
+}
+
+// DownloadWithProgress is like DownloadTo, calling progress as the
+// content is transferred, see ProgressFunc.
+func (c *Client) DownloadWithProgress(path string, w io.Writer, progress ProgressFunc) (int64, error) {
+	start := time.Now()
+	n, err := c.downloadTo(path, w, progress)
+	c.emit(OpDownload, path, n, start, err)
+	return n, err
+}
+
+// downloadTo writes the content of the file at path to w and
+// returns the number of bytes written. If progress is not nil, it is
+// called as the content is transferred.
+func (c *Client) downloadTo(path string, w io.Writer, progress ProgressFunc) (int64, error) {
+	req, err := c.newWebDavRequest("GET", path, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, newStatusError(resp)
+	}
+
+	var body io.Reader = resp.Body
+	if progress != nil {
+		body = &progressReader{r: resp.Body, total: resp.ContentLength, progress: progress}
+	}
+	return io.Copy(w, body)
+}
+
+// DownloadFile writes the content of the file at remotePath to the
+// local file localPath, which is created or truncated. The content is
+// streamed and synced to disk before returning. On failure, the local
+// file is removed, so it is never left with part of the content.
+func (c *Client) DownloadFile(remotePath, localPath string) error {
+	f, err := os.Create(localPath)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.DownloadTo(remotePath, f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(localPath)
+		return err
+	}
+	return nil
+}
+
+// UploadFile uploads the local file localPath to remotePath. The
+// content is streamed, so it is never held entirely in memory.
+func (c *Client) UploadFile(localPath, remotePath string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return c.UploadFromSize(remotePath, f, info.Size())
+}
+
+// UploadFrom uploads the content read from r to dest. The content
+// is streamed, so it is never held entirely in memory.
+func (c *Client) UploadFrom(dest string, r io.Reader) error {
+	return c.UploadFromSize(dest, r, -1)
+}
+
+// UploadFromSize is like UploadFrom but sends size as the length of
+// the content, which some proxies require. A negative size means
+// that the length is unknown.
+func (c *Client) UploadFromSize(dest string, r io.Reader, size int64) error {
+	start := time.Now()
+	counter := &countingReader{r: r}
+
+	// The content of an io.Seeker can be read again to wait for a
+	// locked destination, see LockRetry.
+	var getBody func() (io.ReadCloser, error)
+	if seeker, ok := r.(io.Seeker); ok {
+		offset, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		getBody = func() (io.ReadCloser, error) {
+			if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+				return nil, err
+			}
+			atomic.StoreInt64(&counter.n, 0)
+			return ioutil.NopCloser(counter), nil
+		}
+	}
+
+	err := c.uploadFrom(dest, counter, size, getBody)
+	c.emit(OpUpload, dest, counter.count(), start, err)
+	return err
+}
+
+// UploadWithProgress is like UploadFromSize, calling progress as the
+// content is transferred, see ProgressFunc.
+func (c *Client) UploadWithProgress(dest string, r io.Reader, total int64, progress ProgressFunc) error {
+	return c.UploadFromSize(dest, &progressReader{r: r, total: total, progress: progress}, total)
+}
+
+// uploadFrom uploads the content read from r to dest. A negative
+// size means that the length of the content is unknown. getBody, if
+// not nil, returns the content again when the upload is retried.
+func (c *Client) uploadFrom(dest string, r io.Reader, size int64, getBody func() (io.ReadCloser, error)) error {
+	req, err := c.newWebDavRequest("PUT", dest, r)
+	if err != nil {
+		return err
+	}
+	if size >= 0 {
+		req.ContentLength = size
+	}
+	req.GetBody = getBody
+	return c.sendUpload(req, nil)
+}
+
+// countingReader counts the bytes read from r. The count can be read
+// while the transport is still sending a request body.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	atomic.AddInt64(&r.n, int64(n))
+	return n, err
+}
+
+// count returns the number of bytes read so far.
+func (r *countingReader) count() int64 {
+	return atomic.LoadInt64(&r.n)
+}
+
+// progressInterval is the number of bytes transferred between two
+// calls of a ProgressFunc.
+const progressInterval = 64 * 1024
+
+// ProgressFunc reports the progress of a transfer: transferred bytes
+// out of total. total is -1 when the size is unknown. It is called
+// every progressInterval bytes and once the transfer is complete.
+type ProgressFunc func(transferred, total int64)
+
+// progressReader calls progress as bytes are read from r.
+type progressReader struct {
+	r        io.Reader
+	total    int64
+	n, last  int64
+	progress ProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if r.n != r.last && (r.n-r.last >= progressInterval || r.n == r.total || err == io.EOF) {
+		r.last = r.n
+		r.progress(r.n, r.total)
+	}
+	return n, err
+}
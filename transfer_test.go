@@ -0,0 +1,161 @@
+package cloud
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func (t *testSuite) TestTransfer() {
+	src := newDavServer()
+	defer src.Close()
+	dest := newDavServer()
+	defer dest.Close()
+
+	src.files["/test.txt"] = []byte("Hello World!\n")
+	dest.dirs["/Test"] = true
+
+	err := src.client().Transfer(dest.client(), "test.txt", "Test/test.txt")
+	t.Nil(err)
+	t.Equal("Hello World!\n", string(dest.files["/Test/test.txt"]))
+
+	err = src.client().Transfer(dest.client(), "missing.txt", "Test/missing.txt")
+	t.NotNil(err)
+
+	err = src.client().Transfer(dest.client(), "test.txt", "Missing/test.txt")
+	t.NotNil(err)
+}
+
+func (t *testSuite) TestTransferRejected() {
+	src := newDavServer()
+	defer src.Close()
+	src.files["/test.txt"] = bytes.Repeat([]byte("Hello World!\n"), 1<<20)
+
+	// The destination runs out of space after part of the content.
+	dest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.CopyN(ioutil.Discard, r.Body, 64*1024)
+		w.WriteHeader(http.StatusInsufficientStorage)
+	}))
+	defer dest.Close()
+	d, err := Dial(dest.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	events := make(chan Event, 2)
+	c := src.client()
+	c.Events = events
+	d.Events = events
+
+	err = c.Transfer(d, "test.txt", "test.txt")
+	statusErr, ok := err.(*StatusError)
+	t.True(ok)
+	if ok {
+		t.Equal(http.StatusInsufficientStorage, statusErr.StatusCode)
+	}
+	for i := 0; i < 2; i++ {
+		event := <-events
+		t.Equal(err, event.Err)
+	}
+}
+
+func (t *testSuite) TestUploadFrom() {
+	s := newDavServer()
+	defer s.Close()
+	s.dirs["/Test"] = true
+
+	content := strings.Repeat("Hello World!\n", 1000)
+	pr, pw := io.Pipe()
+	go func() {
+		for i := 0; i < 1000; i++ {
+			io.WriteString(pw, "Hello World!\n")
+		}
+		pw.Close()
+	}()
+
+	t.Nil(s.client().UploadFrom("Test/test.txt", pr))
+	t.Equal(content, string(s.files["/Test/test.txt"]))
+
+	err := s.client().UploadFromSize("Test/sized.txt", strings.NewReader(content), int64(len(content)))
+	t.Nil(err)
+	t.Equal(content, string(s.files["/Test/sized.txt"]))
+
+	t.NotNil(s.client().UploadFrom("Missing/test.txt", strings.NewReader(content)))
+}
+
+func (t *testSuite) TestDownloadTo() {
+	s := newDavServer()
+	defer s.Close()
+	s.files["/test.txt"] = []byte("Hello World!\n")
+
+	var buf bytes.Buffer
+	n, err := s.client().DownloadTo("test.txt", &buf)
+	t.Nil(err)
+	t.Equal(int64(13), n)
+	t.Equal("Hello World!\n", buf.String())
+
+	buf.Reset()
+	n, err = s.client().DownloadTo("missing.txt", &buf)
+	t.NotNil(err)
+	t.Equal(int64(0), n)
+	t.Equal(0, buf.Len())
+}
+
+func (t *testSuite) TestDownloadFile() {
+	s := newDavServer()
+	defer s.Close()
+	s.dirs["/Test"] = true
+	s.files["/test.txt"] = []byte("Hello World!\n")
+
+	dir, err := ioutil.TempDir("", "cloud")
+	t.Nil(err)
+	defer os.RemoveAll(dir)
+	local := filepath.Join(dir, "test.txt")
+
+	t.Nil(s.client().DownloadFile("test.txt", local))
+	data, err := ioutil.ReadFile(local)
+	t.Nil(err)
+	t.Equal("Hello World!\n", string(data))
+
+	t.Nil(s.client().UploadFile(local, "Test/test.txt"))
+	t.Equal("Hello World!\n", string(s.files["/Test/test.txt"]))
+
+	// A failed download doesn't leave a file behind.
+	t.NotNil(s.client().DownloadFile("missing.txt", local))
+	_, err = os.Stat(local)
+	t.True(os.IsNotExist(err))
+
+	t.NotNil(s.client().UploadFile(local, "Test/missing.txt"))
+}
+
+func (t *testSuite) TestProgress() {
+	s := newDavServer()
+	defer s.Close()
+	s.dirs["/Test"] = true
+	content := strings.Repeat("x", 200*1024)
+
+	var calls [][2]int64
+	progress := func(transferred, total int64) {
+		calls = append(calls, [2]int64{transferred, total})
+	}
+
+	err := s.client().UploadWithProgress("Test/big.txt", strings.NewReader(content), int64(len(content)), progress)
+	t.Nil(err)
+	t.Equal(content, string(s.files["/Test/big.txt"]))
+	t.True(len(calls) >= 3)
+	t.True(len(calls) < 10)
+	t.Equal([2]int64{int64(len(content)), int64(len(content))}, calls[len(calls)-1])
+
+	calls = nil
+	var buf bytes.Buffer
+	n, err := s.client().DownloadWithProgress("Test/big.txt", &buf, progress)
+	t.Nil(err)
+	t.Equal(int64(len(content)), n)
+	t.Equal(content, buf.String())
+	t.True(len(calls) >= 3)
+	t.True(len(calls) < 10)
+	t.Equal([2]int64{int64(len(content)), int64(len(content))}, calls[len(calls)-1])
+}
@@ -0,0 +1,139 @@
+package cloud
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// TrashItem is an entry of the user's trash bin.
+type TrashItem struct {
+	// Name is the name of the item inside the trash bin, which
+	// carries the deletion timestamp as a suffix,
+	// e.g. "test.txt.d1600000000".
+	Name string
+
+	// FileName is the name the item had before being deleted.
+	FileName string
+
+	// OriginalLocation is the path the item was deleted from.
+	OriginalLocation string
+
+	// DeletionTime is the time the item was deleted.
+	DeletionTime time.Time
+
+	// Size is the length of a file or the size of the content of
+	// a folder, in bytes.
+	Size int64
+
+	IsDir bool
+}
+
+// trashRoot returns the path of the user's trash bin on the DAV
+// endpoint.
+func (c *Client) trashRoot() string {
+	return path.Join("remote.php/dav/trashbin", c.Username, "trash")
+}
+
+// ListTrash returns the items of the user's trash bin.
+func (c *Client) ListTrash() ([]TrashItem, error) {
+	result, err := c.davPropfind(c.trashRoot(), "1",
+		"<d:resourcetype/><d:getcontentlength/><oc:size/><nc:trashbin-filename/><nc:trashbin-original-location/><nc:trashbin-deletion-time/>")
+	if err != nil {
+		return nil, err
+	}
+
+	var items []TrashItem
+	for _, response := range result.Responses {
+		name, err := c.hrefPath(response.Href, c.trashRoot())
+		if err != nil {
+			return nil, err
+		}
+		if name == "/" {
+			continue
+		}
+		prop := response.prop()
+		item := TrashItem{
+			Name:             strings.TrimPrefix(name, "/"),
+			FileName:         prop.TrashbinFilename,
+			OriginalLocation: prop.TrashbinOriginalLocation,
+			Size:             prop.ContentLength,
+			IsDir:            prop.isCollection(),
+		}
+		if item.IsDir {
+			item.Size = prop.Size
+		}
+		if prop.TrashbinDeletionTime > 0 {
+			item.DeletionTime = time.Unix(prop.TrashbinDeletionTime, 0)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// ListTrashUnder returns the items of the user's trash bin which were
+// deleted from originalPrefix or from any folder below it.
+func (c *Client) ListTrashUnder(originalPrefix string) ([]TrashItem, error) {
+	items, err := c.ListTrash()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.Trim(path.Clean("/"+originalPrefix), "/")
+	var filtered []TrashItem
+	for _, item := range items {
+		location := strings.Trim(item.OriginalLocation, "/")
+		if prefix == "" || location == prefix || strings.HasPrefix(location, prefix+"/") {
+			filtered = append(filtered, item)
+		}
+	}
+
+	return filtered, nil
+}
+
+// RestoreFromTrash moves the given trash item back to its original
+// location.
+func (c *Client) RestoreFromTrash(item TrashItem) error {
+	return c.moveFromTrash(item, path.Join("remote.php/dav/trashbin", c.Username, "restore", item.Name))
+}
+
+// RestoreFromTrashTo moves the given trash item to dest instead of
+// its original location, creating the missing parents of dest. This
+// is useful when the original parent folder doesn't exist anymore.
+func (c *Client) RestoreFromTrashTo(item TrashItem, dest string) error {
+	if err := c.MkdirAll(path.Dir(path.Clean("/" + dest))); err != nil {
+		return err
+	}
+	return c.moveFromTrash(item, path.Join(c.filesRoot(), dest))
+}
+
+// moveFromTrash moves the given trash item to davPath, a path of the
+// DAV endpoint.
+func (c *Client) moveFromTrash(item TrashItem, davPath string) error {
+	req, err := c.newRequest("MOVE", path.Join(c.trashRoot(), item.Name), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Destination", c.resolve(davPath))
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return newStatusError(resp)
+	}
+
+	return nil
+}
+
+// EmptyTrash permanently deletes all the items of the user's trash
+// bin.
+func (c *Client) EmptyTrash() error {
+	return c.deleteDav(context.Background(), c.trashRoot())
+}
@@ -0,0 +1,178 @@
+package cloud
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+func (t *testSuite) TestRestoreFromTrashTo() {
+	var requests []string
+	var destination string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.Method {
+		case "MKCOL":
+			w.WriteHeader(http.StatusCreated)
+		case "MOVE":
+			destination = r.Header.Get("Destination")
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	err = c.RestoreFromTrashTo(TrashItem{Name: "test.txt.d1600000000"}, "Restored/Folder/test.txt")
+	t.Nil(err)
+	t.Equal([]string{
+		"MKCOL /remote.php/webdav/Restored",
+		"MKCOL /remote.php/webdav/Restored/Folder",
+		"MOVE /remote.php/dav/trashbin/admin/trash/test.txt.d1600000000",
+	}, requests)
+	t.Equal(ts.URL+"/remote.php/dav/files/admin/Restored/Folder/test.txt", destination)
+}
+
+// trashResponse is the multistatus listing of a trash bin holding
+// a file and a folder.
+const trashResponse = `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns" xmlns:nc="http://nextcloud.org/ns">
+ <d:response>
+  <d:href>/remote.php/dav/trashbin/admin/trash/</d:href>
+  <d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+ <d:response>
+  <d:href>/remote.php/dav/trashbin/admin/trash/test.txt.d1600000000</d:href>
+  <d:propstat><d:prop><d:resourcetype/><d:getcontentlength>13</d:getcontentlength><nc:trashbin-filename>test.txt</nc:trashbin-filename><nc:trashbin-original-location>Test/Folder/test.txt</nc:trashbin-original-location><nc:trashbin-deletion-time>1600000000</nc:trashbin-deletion-time></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+ <d:response>
+  <d:href>/remote.php/dav/trashbin/admin/trash/Test2.d1600000001/</d:href>
+  <d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype><oc:size>42</oc:size><nc:trashbin-filename>Test2</nc:trashbin-filename><nc:trashbin-original-location>Test2</nc:trashbin-original-location><nc:trashbin-deletion-time>1600000001</nc:trashbin-deletion-time></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+ </d:response>
+</d:multistatus>`
+
+func (t *testSuite) TestListTrash() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Equal("PROPFIND", r.Method)
+		t.Equal("/remote.php/dav/trashbin/admin/trash", r.URL.Path)
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprint(w, trashResponse)
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	file := TrashItem{
+		Name:             "test.txt.d1600000000",
+		FileName:         "test.txt",
+		OriginalLocation: "Test/Folder/test.txt",
+		DeletionTime:     time.Unix(1600000000, 0),
+		Size:             13,
+	}
+	folder := TrashItem{
+		Name:             "Test2.d1600000001",
+		FileName:         "Test2",
+		OriginalLocation: "Test2",
+		DeletionTime:     time.Unix(1600000001, 0),
+		Size:             42,
+		IsDir:            true,
+	}
+
+	items, err := c.ListTrash()
+	t.Nil(err)
+	t.Equal([]TrashItem{file, folder}, items)
+
+	items, err = c.ListTrashUnder("/Test")
+	t.Nil(err)
+	t.Equal([]TrashItem{file}, items)
+
+	items, err = c.ListTrashUnder("Test2")
+	t.Nil(err)
+	t.Equal([]TrashItem{folder}, items)
+}
+
+// trashServer is a WebDAV server holding files and a trash bin, in
+// which the deleted files are moved.
+type trashServer struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	files map[string]string
+	trash map[string]string
+}
+
+func newTrashServer() *trashServer {
+	s := &trashServer{files: make(map[string]string), trash: make(map[string]string)}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+func (s *trashServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	const filesRoot, trashRoot = "/remote.php/webdav/", "/remote.php/dav/trashbin/admin/trash"
+	switch {
+	case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, filesRoot):
+		name := strings.TrimPrefix(r.URL.Path, filesRoot)
+		s.trash[name+".d1600000000"] = s.files[name]
+		delete(s.files, name)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "DELETE" && r.URL.Path == trashRoot:
+		s.trash = make(map[string]string)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "PROPFIND" && r.URL.Path == trashRoot:
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprint(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:nc="http://nextcloud.org/ns">`)
+		for name, data := range s.trash {
+			location := strings.TrimSuffix(name, ".d1600000000")
+			fmt.Fprintf(w, `<d:response><d:href>%s/%s</d:href><d:propstat><d:prop><d:resourcetype/><d:getcontentlength>%d</d:getcontentlength><nc:trashbin-filename>%s</nc:trashbin-filename><nc:trashbin-original-location>%s</nc:trashbin-original-location><nc:trashbin-deletion-time>1600000000</nc:trashbin-deletion-time></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`, trashRoot, name, len(data), path.Base(location), location)
+		}
+		fmt.Fprint(w, `</d:multistatus>`)
+	case r.Method == "MOVE" && strings.HasPrefix(r.URL.Path, trashRoot+"/"):
+		name := strings.TrimPrefix(r.URL.Path, trashRoot+"/")
+		data, ok := s.trash[name]
+		if !ok || r.Header.Get("Destination") != s.URL+"/remote.php/dav/trashbin/admin/restore/"+name {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.files[strings.TrimSuffix(name, ".d1600000000")] = data
+		delete(s.trash, name)
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (t *testSuite) TestRestoreFromTrash() {
+	s := newTrashServer()
+	defer s.Close()
+	s.files["test.txt"] = "Hello World!\n"
+	s.files["other.txt"] = "Hello"
+	c, err := Dial(s.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	t.Nil(c.Delete("test.txt"))
+	items, err := c.ListTrash()
+	t.Nil(err)
+	t.Equal(1, len(items))
+	if len(items) == 1 {
+		t.Equal("test.txt", items[0].OriginalLocation)
+		t.Equal(int64(13), items[0].Size)
+		t.Nil(c.RestoreFromTrash(items[0]))
+	}
+	t.Equal("Hello World!\n", s.files["test.txt"])
+	t.Equal(0, len(s.trash))
+
+	t.Nil(c.Delete("other.txt"))
+	t.Nil(c.EmptyTrash())
+	items, err = c.ListTrash()
+	t.Nil(err)
+	t.Equal(0, len(items))
+}
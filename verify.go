@@ -0,0 +1,63 @@
+package cloud
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrUnauthorized is returned by Verify when the server refuses the
+// credentials of the client.
+var ErrUnauthorized = errors.New("invalid credentials")
+
+// UnreachableError is returned by Verify when the server can't be
+// reached, e.g. because of a typo in its host name.
+type UnreachableError struct {
+	Err error
+}
+
+func (e *UnreachableError) Error() string {
+	return "server unreachable: " + e.Err.Error()
+}
+
+func (e *UnreachableError) Unwrap() error {
+	return e.Err
+}
+
+// DialAndVerify is like Dial, then checks with Verify that the server
+// is reachable and accepts the credentials, instead of leaving it to
+// the first operation.
+func DialAndVerify(host, username, password string) (*Client, error) {
+	c, err := Dial(host, username, password)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Verify(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Verify sends a lightweight PROPFIND to the root folder of the user.
+// It returns an *UnreachableError if the server can't be reached and
+// ErrUnauthorized if it refuses the credentials.
+func (c *Client) Verify() error {
+	req, err := c.newWebDavRequest("PROPFIND", "", nil)
+	if err != nil {
+		return err
+	}
+	setPropfindBody(req, "0", []string{"<d:resourcetype/>"})
+
+	resp, err := c.do(req)
+	if err != nil {
+		return &UnreachableError{Err: err}
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case resp.StatusCode != http.StatusMultiStatus:
+		return newStatusError(resp)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package cloud
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+func (t *testSuite) TestDialAndVerify() {
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+r.Header.Get("Depth"))
+		if username, password, _ := r.BasicAuth(); username != "admin" || password != "password" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprint(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:"><d:response><d:href>/remote.php/webdav/</d:href><d:propstat><d:prop><d:resourcetype><d:collection/></d:resourcetype></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response></d:multistatus>`)
+	}))
+	defer ts.Close()
+
+	c, err := DialAndVerify(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+	t.NotNil(c)
+	t.Equal([]string{"PROPFIND /remote.php/webdav 0"}, requests)
+
+	_, err = DialAndVerify(ts.URL+"/", "admin", "wrong")
+	t.Equal(ErrUnauthorized, err)
+
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	_, err = DialAndVerify(unreachable.URL+"/", "admin", "password")
+	var unreachableErr *UnreachableError
+	t.True(errors.As(err, &unreachableErr))
+}
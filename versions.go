@@ -0,0 +1,159 @@
+package cloud
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+)
+
+// ErrVersioningDisabled is returned when the versions app is not
+// enabled on the server.
+var ErrVersioningDisabled = errors.New("file versioning is disabled")
+
+// FileVersion is a previous version of a file kept by the server.
+type FileVersion struct {
+	// Href is the path of the version on the DAV endpoint, as
+	// returned by the server.
+	Href string
+
+	// Timestamp is the time the version was replaced.
+	Timestamp time.Time
+
+	// Size is the length of the version in bytes.
+	Size int64
+}
+
+// versionsRoot returns the path of the versions of the file with the
+// given id on the DAV endpoint.
+func (c *Client) versionsRoot(fileId string) string {
+	return path.Join("remote.php/dav/versions", c.Username, "versions", fileId)
+}
+
+// ListVersions returns the previous versions of the file at p. If
+// versioning is disabled on the server, ErrVersioningDisabled is
+// returned.
+func (c *Client) ListVersions(p string) ([]FileVersion, error) {
+	fileId, err := c.fileId(p)
+	if err != nil {
+		return nil, err
+	}
+
+	root := c.versionsRoot(fileId)
+	result, err := c.davPropfind(root, "1", "<d:getcontentlength/><d:getlastmodified/>")
+	if statusErr, ok := err.(*StatusError); ok && statusErr.StatusCode == http.StatusNotFound {
+		return nil, ErrVersioningDisabled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []FileVersion
+	for _, response := range result.Responses {
+		name, err := c.hrefPath(response.Href, root)
+		if err != nil {
+			return nil, err
+		}
+		if name == "/" {
+			continue
+		}
+		prop := response.prop()
+		version := FileVersion{Href: response.Href, Size: prop.ContentLength}
+		// Versions are named after the time they were replaced.
+		if sec, err := strconv.ParseInt(path.Base(name), 10, 64); err == nil {
+			version.Timestamp = time.Unix(sec, 0)
+		} else if t, err := http.ParseTime(prop.LastModified); err == nil {
+			version.Timestamp = t
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// RestoreVersion makes the given version the current content of the
+// file at p. The current content becomes a version in turn.
+func (c *Client) RestoreVersion(p string, v FileVersion) error {
+	fileId, err := c.fileId(p)
+	if err != nil {
+		return err
+	}
+	href, err := url.PathUnescape(v.Href)
+	if err != nil {
+		return err
+	}
+	// Make sure that the version belongs to p.
+	root := c.Url.ResolveReference(&url.URL{Path: c.versionsRoot(fileId)}).Path
+	if path.Dir(href) != root {
+		return fmt.Errorf("%s is not a version of %s", v.Href, p)
+	}
+
+	req, err := c.newRequest("MOVE", href, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Destination", c.resolve(path.Join("remote.php/dav/versions", c.Username, "restore/target")))
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return newStatusError(resp)
+	}
+
+	return nil
+}
+
+// DownloadVersion returns the content of the given version without
+// restoring it.
+func (c *Client) DownloadVersion(v FileVersion) ([]byte, error) {
+	var buf bytes.Buffer
+	_, err := c.DownloadVersionTo(v, &buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DownloadVersionTo writes the content of the given version to w
+// and returns the number of bytes written.
+func (c *Client) DownloadVersionTo(v FileVersion, w io.Writer) (int64, error) {
+	p, err := url.PathUnescape(v.Href)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	n, err := c.downloadVersionTo(p, w)
+	c.emit(OpDownload, p, n, start, err)
+	return n, err
+}
+
+// downloadVersionTo writes the content of the version at the server
+// path p to w and returns the number of bytes written.
+func (c *Client) downloadVersionTo(p string, w io.Writer) (int64, error) {
+	req, err := c.newRequest("GET", p, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, newStatusError(resp)
+	}
+
+	return io.Copy(w, resp.Body)
+}
@@ -0,0 +1,74 @@
+package cloud
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+func (t *testSuite) TestDownloadVersion() {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/remote.php/dav/versions/admin/versions/42/1600000000" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "Hello World!\n")
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	data, err := c.DownloadVersion(FileVersion{Href: "/remote.php/dav/versions/admin/versions/42/1600000000"})
+	t.Nil(err)
+	t.Equal("Hello World!\n", string(data))
+
+	_, err = c.DownloadVersion(FileVersion{Href: "/remote.php/dav/versions/admin/versions/42/1"})
+	t.NotNil(err)
+}
+
+func (t *testSuite) TestListVersions() {
+	var restored string
+	versioning := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "PROPFIND" && r.URL.Path == "/remote.php/webdav/Test/test.txt":
+			w.WriteHeader(http.StatusMultiStatus)
+			fmt.Fprint(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:oc="http://owncloud.org/ns"><d:response><d:href>/remote.php/webdav/Test/test.txt</d:href><d:propstat><d:prop><oc:fileid>42</oc:fileid></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response></d:multistatus>`)
+		case r.Method == "PROPFIND" && r.URL.Path == "/remote.php/dav/versions/admin/versions/42" && versioning:
+			w.WriteHeader(http.StatusMultiStatus)
+			fmt.Fprint(w, `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:">
+ <d:response><d:href>/remote.php/dav/versions/admin/versions/42/</d:href><d:propstat><d:prop/><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>
+ <d:response><d:href>/remote.php/dav/versions/admin/versions/42/1600000000</d:href><d:propstat><d:prop><d:getcontentlength>13</d:getcontentlength></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>
+</d:multistatus>`)
+		case r.Method == "MOVE":
+			t.Equal("http://"+r.Host+"/remote.php/dav/versions/admin/restore/target", r.Header.Get("Destination"))
+			restored = r.URL.Path
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	c, err := Dial(ts.URL+"/", "admin", "password")
+	t.Nil(err)
+
+	versions, err := c.ListVersions("Test/test.txt")
+	t.Nil(err)
+	t.Equal([]FileVersion{{
+		Href:      "/remote.php/dav/versions/admin/versions/42/1600000000",
+		Timestamp: time.Unix(1600000000, 0),
+		Size:      13,
+	}}, versions)
+
+	t.Nil(c.RestoreVersion("Test/test.txt", versions[0]))
+	t.Equal("/remote.php/dav/versions/admin/versions/42/1600000000", restored)
+	t.NotNil(c.RestoreVersion("Test/test.txt", FileVersion{Href: "/remote.php/dav/versions/admin/versions/7/1600000000"}))
+
+	versioning = false
+	_, err = c.ListVersions("Test/test.txt")
+	t.Equal(ErrVersioningDisabled, err)
+}